@@ -0,0 +1,227 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"io"
+	"strings"
+
+	"github.com/medivhyang/golib/database/orm"
+	"github.com/medivhyang/golib/string/naming"
+)
+
+type Options struct {
+	Package   string
+	Constants bool
+	Relations bool
+}
+
+const ormImportPath = "github.com/medivhyang/golib/database/orm"
+
+var abbrs = []string{"id", "ip", "url", "uri", "uuid", "api", "http", "json", "html", "sql", "xml"}
+
+func generate(w io.Writer, tables []orm.TableInfo, options Options) error {
+	if options.Package == "" {
+		options.Package = "models"
+	}
+	names := make(map[string]string, len(tables))
+	for _, t := range tables {
+		names[t.Name] = goName(t.Name)
+	}
+
+	var body bytes.Buffer
+	imports := map[string]bool{}
+	for _, t := range tables {
+		generateTable(&body, t, names, options, imports)
+	}
+
+	var out bytes.Buffer
+	fmt.Fprintf(&out, "// Code generated by ormgen. DO NOT EDIT.\n\npackage %s\n\n", options.Package)
+	if len(imports) > 0 {
+		out.WriteString("import (\n")
+		if imports["time"] {
+			out.WriteString("\t\"time\"\n\n")
+		}
+		if imports[ormImportPath] {
+			fmt.Fprintf(&out, "\t%q\n", ormImportPath)
+		}
+		out.WriteString(")\n\n")
+	}
+	out.Write(body.Bytes())
+
+	src, err := format.Source(out.Bytes())
+	if err != nil {
+		return fmt.Errorf("ormgen: format source: %w", err)
+	}
+	_, err = w.Write(src)
+	return err
+}
+
+func generateTable(w *bytes.Buffer, t orm.TableInfo, names map[string]string, options Options, imports map[string]bool) {
+	name := names[t.Name]
+	primaryKeys := 0
+	for _, c := range t.Columns {
+		if c.PrimaryKey {
+			primaryKeys++
+		}
+	}
+
+	fmt.Fprintf(w, "// %s is generated from table %s.\n", name, t.Name)
+	if len(t.Indexes) > 0 || len(t.ForeignKeys) > 0 || primaryKeys > 1 {
+		w.WriteString("//\n")
+	}
+	if primaryKeys > 1 {
+		var columns []string
+		for _, c := range t.Columns {
+			if c.PrimaryKey {
+				columns = append(columns, c.Name)
+			}
+		}
+		fmt.Fprintf(w, "//\tprimary key (%s)\n", strings.Join(columns, ", "))
+	}
+	for _, index := range t.Indexes {
+		kind := "index"
+		if index.Unique {
+			kind = "unique index"
+		}
+		fmt.Fprintf(w, "//\t%s %s (%s)\n", kind, index.Name, strings.Join(index.Columns, ", "))
+	}
+	for _, fk := range t.ForeignKeys {
+		fmt.Fprintf(w, "//\tforeign key (%s) references %s (%s)\n", strings.Join(fk.Columns, ", "), fk.RefTable, strings.Join(fk.RefColumns, ", "))
+	}
+
+	fields := map[string]bool{"Table": true}
+	columnFields := make([]string, len(t.Columns))
+	fmt.Fprintf(w, "type %s struct {\n", name)
+	for i, c := range t.Columns {
+		field := uniqueName(goName(c.Name), fields)
+		fields[field] = true
+		columnFields[i] = field
+		goType := goType(c.Type, c.Nullable)
+		if strings.Contains(goType, "time.") {
+			imports["time"] = true
+		}
+		tag, ok := columnTag(c, primaryKeys == 1)
+		if !ok {
+			fmt.Fprintf(w, "\t%s %s `orm:%q` // %s\n", field, goType, tag, c.Type)
+			continue
+		}
+		fmt.Fprintf(w, "\t%s %s `orm:%q`\n", field, goType, tag)
+	}
+	if options.Relations {
+		for _, fk := range t.ForeignKeys {
+			target, ok := names[fk.RefTable]
+			if !ok || len(fk.Columns) != 1 {
+				continue
+			}
+			field := goName(strings.TrimSuffix(fk.Columns[0], "_id"))
+			if fields[field] {
+				field = target + "Ref"
+			}
+			if fields[field] {
+				continue
+			}
+			fields[field] = true
+			tag := ",belongs_to,foreign_key=" + fk.Columns[0]
+			if fk.RefColumns[0] != "" {
+				tag += ",references=" + fk.RefColumns[0]
+			}
+			fmt.Fprintf(w, "\t%s *%s `orm:%q`\n", field, target, tag)
+		}
+	}
+	w.WriteString("}\n\n")
+
+	fmt.Fprintf(w, "func (%s) Table() string {\n\treturn %q\n}\n\n", name, t.Name)
+
+	if options.Constants {
+		imports[ormImportPath] = true
+		w.WriteString("const (\n")
+		for i, c := range t.Columns {
+			fmt.Fprintf(w, "\t%s%s orm.ColumnRef = %q\n", name, columnFields[i], c.Name)
+		}
+		w.WriteString(")\n\n")
+	}
+}
+
+// sqlTypeAliases are single word spellings of multi word types, tags split the type and the suffix by spaces.
+var sqlTypeAliases = []struct{ from, to string }{
+	{"character varying", "varchar"},
+	{"double precision", "double"},
+}
+
+// columnTag returns the orm tag of c, which is only the column name if the type can't be written as one word.
+func columnTag(c orm.ColumnInfo, singlePrimaryKey bool) (string, bool) {
+	sqlType := strings.ReplaceAll(strings.TrimSpace(c.Type), ", ", ",")
+	for _, alias := range sqlTypeAliases {
+		if strings.HasPrefix(strings.ToLower(sqlType), alias.from) {
+			sqlType = alias.to + sqlType[len(alias.from):]
+		}
+	}
+	if sqlType == "" {
+		return c.Name, true
+	}
+	if strings.ContainsAny(sqlType, " \t") {
+		return c.Name, false
+	}
+	items := []string{c.Name, sqlType}
+	switch {
+	case c.PrimaryKey && singlePrimaryKey:
+		items = append(items, "primary key")
+	case !c.Nullable:
+		items = append(items, "not null")
+	}
+	return strings.Join(items, " "), true
+}
+
+// uniqueName returns name, or name with the smallest number suffix from 2 that is not in taken.
+func uniqueName(name string, taken map[string]bool) string {
+	result := name
+	for i := 2; taken[result]; i++ {
+		result = fmt.Sprintf("%s%d", name, i)
+	}
+	return result
+}
+
+func goName(s string) string {
+	name := naming.ToCase(naming.CasePascal, s, abbrs...)
+	if name == "" || !isLetter(name[0]) {
+		name = "X" + name
+	}
+	return name
+}
+
+func isLetter(b byte) bool {
+	return b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z' || b == '_'
+}
+
+// goType maps a column type to a Go type by the type affinity rules of SQLite,
+// which also fit the common types of other databases.
+func goType(sqlType string, nullable bool) string {
+	t := strings.ToLower(sqlType)
+	var result string
+	switch {
+	case t == "":
+		return "interface{}"
+	case strings.Contains(t, "bool"), strings.HasPrefix(t, "tinyint(1)"), t == "bit(1)":
+		result = "bool"
+	case strings.Contains(t, "int"):
+		result = "int64"
+	case strings.Contains(t, "char"), strings.Contains(t, "clob"), strings.Contains(t, "text"),
+		strings.Contains(t, "json"), strings.HasPrefix(t, "enum"), strings.HasPrefix(t, "set"):
+		result = "string"
+	case strings.Contains(t, "blob"), strings.Contains(t, "binary"):
+		return "[]byte"
+	case strings.Contains(t, "date"), strings.Contains(t, "time"):
+		result = "time.Time"
+	case strings.Contains(t, "real"), strings.Contains(t, "floa"), strings.Contains(t, "doub"),
+		strings.Contains(t, "dec"), strings.Contains(t, "num"):
+		result = "float64"
+	default:
+		result = "string"
+	}
+	if nullable {
+		return "*" + result
+	}
+	return result
+}
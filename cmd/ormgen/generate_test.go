@@ -0,0 +1,103 @@
+package main
+
+import (
+	"os"
+
+	"github.com/medivhyang/golib/database/orm"
+)
+
+func Example_generate() {
+	tables := []orm.TableInfo{
+		{
+			Name: "user_account",
+			Columns: []orm.ColumnInfo{
+				{Name: "id", Type: "integer", PrimaryKey: true},
+				{Name: "org_id", Type: "integer", Nullable: true},
+				{Name: "email", Type: "varchar(255)"},
+			},
+			Indexes:     []orm.IndexInfo{{Name: "idx_email", Columns: []string{"email"}, Unique: true}},
+			ForeignKeys: []orm.ForeignKeyInfo{{Columns: []string{"org_id"}, RefTable: "org", RefColumns: []string{"id"}}},
+		},
+	}
+	if err := generate(os.Stdout, tables, Options{Constants: true}); err != nil {
+		panic(err)
+	}
+	// output:
+	// // Code generated by ormgen. DO NOT EDIT.
+	//
+	// package models
+	//
+	// import (
+	// 	"github.com/medivhyang/golib/database/orm"
+	// )
+	//
+	// // UserAccount is generated from table user_account.
+	// //
+	// //	unique index idx_email (email)
+	// //	foreign key (org_id) references org (id)
+	// type UserAccount struct {
+	// 	ID    int64  `orm:"id integer primary key"`
+	// 	OrgID *int64 `orm:"org_id integer"`
+	// 	Email string `orm:"email varchar(255) not null"`
+	// }
+	//
+	// func (UserAccount) Table() string {
+	// 	return "user_account"
+	// }
+	//
+	// const (
+	// 	UserAccountID    orm.ColumnRef = "id"
+	// 	UserAccountOrgID orm.ColumnRef = "org_id"
+	// 	UserAccountEmail orm.ColumnRef = "email"
+	// )
+}
+
+func Example_generateColumnNames() {
+	tables := []orm.TableInfo{
+		{
+			Name: "metric",
+			Columns: []orm.ColumnInfo{
+				{Name: "user_id", Type: "integer"},
+				{Name: "userId", Type: "integer"},
+				{Name: "table", Type: "text"},
+				{Name: "score", Type: "double precision"},
+				{Name: "label", Type: "character varying(255)", Nullable: true},
+				{Name: "hits", Type: "int unsigned"},
+			},
+		},
+	}
+	if err := generate(os.Stdout, tables, Options{Constants: true}); err != nil {
+		panic(err)
+	}
+	// output:
+	// // Code generated by ormgen. DO NOT EDIT.
+	//
+	// package models
+	//
+	// import (
+	// 	"github.com/medivhyang/golib/database/orm"
+	// )
+	//
+	// // Metric is generated from table metric.
+	// type Metric struct {
+	// 	UserID  int64   `orm:"user_id integer not null"`
+	// 	UserID2 int64   `orm:"userId integer not null"`
+	// 	Table2  string  `orm:"table text not null"`
+	// 	Score   float64 `orm:"score double not null"`
+	// 	Label   *string `orm:"label varchar(255)"`
+	// 	Hits    int64   `orm:"hits"` // int unsigned
+	// }
+	//
+	// func (Metric) Table() string {
+	// 	return "metric"
+	// }
+	//
+	// const (
+	// 	MetricUserID  orm.ColumnRef = "user_id"
+	// 	MetricUserID2 orm.ColumnRef = "userId"
+	// 	MetricTable2  orm.ColumnRef = "table"
+	// 	MetricScore   orm.ColumnRef = "score"
+	// 	MetricLabel   orm.ColumnRef = "label"
+	// 	MetricHits    orm.ColumnRef = "hits"
+	// )
+}
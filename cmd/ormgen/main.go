@@ -0,0 +1,79 @@
+// Command ormgen generates orm models from the tables of an existing database, e.g.
+//
+//	ormgen -driver sqlite3 -dsn app.db -pkg models -out models/models.go -constants
+//
+// The database driver must be linked into the binary, this build includes sqlite3.
+package main
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/medivhyang/golib/database/orm"
+	_ "github.com/medivhyang/golib/database/orm/dialect/mysql"
+	_ "github.com/medivhyang/golib/database/orm/dialect/sqlite3"
+)
+
+func main() {
+	var (
+		driver    = flag.String("driver", "sqlite3", "database driver and dialect name")
+		dsn       = flag.String("dsn", "", "data source name")
+		pkg       = flag.String("pkg", "models", "package name of the generated file")
+		out       = flag.String("out", "", "output file, defaults to stdout")
+		tables    = flag.String("tables", "", "comma separated tables, defaults to all tables")
+		constants = flag.Bool("constants", false, "generate typed column name constants")
+		relations = flag.Bool("relations", false, "generate belongs_to fields from foreign keys")
+	)
+	flag.Parse()
+	if *dsn == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if err := run(*driver, *dsn, *out, splitTables(*tables), Options{Package: *pkg, Constants: *constants, Relations: *relations}); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(driver string, dsn string, out string, tables []string, options Options) error {
+	dialect, ok := orm.LookupDialect(driver)
+	if !ok {
+		return fmt.Errorf("ormgen: not found dialect %s", driver)
+	}
+	raw, err := sql.Open(driver, dsn)
+	if err != nil {
+		return err
+	}
+	defer raw.Close()
+
+	infos, err := orm.Inspect(context.Background(), orm.NewDB(dialect, raw), tables...)
+	if err != nil {
+		return err
+	}
+
+	var w io.Writer = os.Stdout
+	if out != "" {
+		f, err := os.Create(out)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+	return generate(w, infos, options)
+}
+
+func splitTables(s string) []string {
+	var result []string
+	for _, t := range strings.Split(s, ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			result = append(result, t)
+		}
+	}
+	return result
+}
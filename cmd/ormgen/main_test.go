@@ -0,0 +1,59 @@
+package main
+
+import (
+	"database/sql"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRun(t *testing.T) {
+	dir := t.TempDir()
+	dsn := filepath.Join(dir, "app.db")
+	raw, err := sql.Open("sqlite3", dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer raw.Close()
+	for _, stmt := range []string{
+		`create table org (id integer primary key, name text not null)`,
+		`create table user_account (id integer primary key, org_id integer references org(id), email varchar(255) not null)`,
+		`create unique index idx_email on user_account(email)`,
+	} {
+		if _, err := raw.Exec(stmt); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	out := filepath.Join(dir, "models.go")
+	if err := run("sqlite3", dsn, out, []string{"user_account"}, Options{Package: "models"}); err != nil {
+		t.Fatal(err)
+	}
+	b, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := string(b)
+	for _, want := range []string{
+		"package models\n",
+		"type UserAccount struct {",
+		"//\tunique index idx_email (email)",
+		"//\tforeign key (org_id) references org (id)",
+		"ID    int64  `orm:\"id INTEGER primary key\"`",
+		"OrgID *int64 `orm:\"org_id INTEGER\"`",
+		"Email string `orm:\"email varchar(255) not null\"`",
+		"func (UserAccount) Table() string {\n\treturn \"user_account\"\n}",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("missing %q in:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "type Org struct") {
+		t.Errorf("got table org which is not requested:\n%s", got)
+	}
+
+	if err := run("unknown", dsn, out, nil, Options{}); err == nil {
+		t.Fatal("got nil error for an unknown dialect")
+	}
+}
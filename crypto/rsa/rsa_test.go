@@ -1,29 +1,33 @@
 package rsa
 
 import (
+	"path/filepath"
 	"testing"
 )
 
 func TestGenerateKeyPairFiles(t *testing.T) {
-	if err := GenerateKeyFiles(2048, "public.pem", "private.pem"); err != nil {
+	dir := t.TempDir()
+	if err := GenerateKeyFiles(2048, filepath.Join(dir, "public.pem"), filepath.Join(dir, "private.pem")); err != nil {
 		t.Error(err)
 	}
 }
 
 func TestEncryptAndDecryptWithFile(t *testing.T) {
-	if err := GenerateKeyFiles(2048, "public.pem", "private.pem"); err != nil {
+	dir := t.TempDir()
+	publicFile, privateFile := filepath.Join(dir, "public.pem"), filepath.Join(dir, "private.pem")
+	if err := GenerateKeyFiles(2048, publicFile, privateFile); err != nil {
 		t.Error(err)
 		return
 	}
 
 	plainText := "hello world"
-	cipherText, err := EncryptWithFile([]byte(plainText), "public.pem")
+	cipherText, err := EncryptWithFile([]byte(plainText), publicFile)
 	if err != nil {
 		t.Error(err)
 		return
 	}
 
-	result, err := DecryptWithFile(cipherText, "private.pem")
+	result, err := DecryptWithFile(cipherText, privateFile)
 	if err != nil {
 		t.Error(err)
 		return
@@ -0,0 +1,96 @@
+package orm
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+func (b *Builder) Count(ctx context.Context, db DBTX) (int64, error) {
+	var n int64
+	if err := b.aggregate(ctx, db, "count(*)", &n); err != nil {
+		return 0, err
+	}
+	return n, nil
+}
+
+func (b *Builder) Sum(ctx context.Context, db DBTX, column string) (float64, error) {
+	return b.aggregateFloat(ctx, db, "sum", column)
+}
+
+func (b *Builder) Avg(ctx context.Context, db DBTX, column string) (float64, error) {
+	return b.aggregateFloat(ctx, db, "avg", column)
+}
+
+func (b *Builder) Max(ctx context.Context, db DBTX, column string, dst interface{}) error {
+	return b.aggregate(ctx, db, fmt.Sprintf("max(%s)", quoteColumn(b.dialect, column)), dst)
+}
+
+func (b *Builder) Min(ctx context.Context, db DBTX, column string, dst interface{}) error {
+	return b.aggregate(ctx, db, fmt.Sprintf("min(%s)", quoteColumn(b.dialect, column)), dst)
+}
+
+func (b *Builder) aggregateFloat(ctx context.Context, db DBTX, fn string, column string) (float64, error) {
+	var v sql.NullFloat64
+	if err := b.aggregate(ctx, db, fmt.Sprintf("%s(%s)", fn, quoteColumn(b.dialect, column)), &v); err != nil {
+		return 0, err
+	}
+	return v.Float64, nil
+}
+
+// aggregate queries expr over the rows of b, ignoring its order and paging,
+// grouped, distinct or union queries are wrapped as a subquery.
+func (b *Builder) aggregate(ctx context.Context, db DBTX, expr string, dst interface{}) error {
+	if b.err != nil {
+		return b.err
+	}
+	if b.action != actionSelect {
+		return errorf("aggregate: require select action")
+	}
+	c := b.Clone()
+	c.orderBy = nil
+	c.paging = Template{}
+	c.preloads = nil
+	if len(c.groupBy) > 0 || c.distinct || len(c.unions) > 0 {
+		return New(b.dialect).Select(c.As("t").WithContext(ctx), expr).Query(ctx, db, dst)
+	}
+	c.columns = Templates{NewTemplate(expr)}
+	return c.Query(ctx, db, dst)
+}
+
+type Page[T any] struct {
+	Items      []T   `json:"items"`
+	Total      int64 `json:"total"`
+	Page       int   `json:"page"`
+	Size       int   `json:"size"`
+	TotalPages int   `json:"total_pages"`
+	HasNext    bool  `json:"has_next"`
+}
+
+func Paginate[T any](ctx context.Context, db DBTX, b *Builder, page, size int) (*Page[T], error) {
+	if page < 1 {
+		page = 1
+	}
+	if size < 1 {
+		return nil, errorf("paginate: invalid page size %d", size)
+	}
+	total, err := b.Count(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]T, 0, size)
+	if total > int64((page-1)*size) {
+		if err := b.Clone().Paging(page, size).Query(ctx, db, &items); err != nil {
+			return nil, err
+		}
+	}
+	totalPages := int((total + int64(size) - 1) / int64(size))
+	return &Page[T]{
+		Items:      items,
+		Total:      total,
+		Page:       page,
+		Size:       size,
+		TotalPages: totalPages,
+		HasNext:    page < totalPages,
+	}, nil
+}
@@ -0,0 +1,88 @@
+package orm
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// aggregateTestDB prints the queries it receives, it returns the ids of the requested page for paged queries,
+// and the total for the others.
+type aggregateTestDB struct {
+	DBTX
+	total int
+}
+
+func (db *aggregateTestDB) Dialect() Dialect {
+	return &TestDialect{}
+}
+
+func (db *aggregateTestDB) Query(ctx context.Context, t Template, i interface{}) error {
+	rows, err := db.QueryRows(ctx, t)
+	if err != nil {
+		return err
+	}
+	return rows.Bind(i)
+}
+
+func (db *aggregateTestDB) QueryRows(ctx context.Context, t Template) (*Rows, error) {
+	fmt.Printf("%q %v\n", t.Format, t.Values)
+	if !strings.HasSuffix(t.Format, "limit ?,?") {
+		return NewValueRows(&TestDialect{}, []string{"total"}, [][]interface{}{{int64(db.total)}})
+	}
+	var values [][]interface{}
+	offset, size := t.Values[len(t.Values)-2].(int), t.Values[len(t.Values)-1].(int)
+	for id := offset + 1; id <= offset+size && id <= db.total; id++ {
+		values = append(values, []interface{}{int64(id)})
+	}
+	return NewValueRows(&TestDialect{}, []string{"id"}, values)
+}
+
+func ExampleBuilder_Count() {
+	ctx := context.Background()
+	db := &aggregateTestDB{total: 5}
+
+	n, err := New(&TestDialect{}).Select("user").Where("age > ?", 18).OrderBy("id desc").Paging(2, 10).Count(ctx, db)
+	fmt.Println(n, err)
+	n, err = New(&TestDialect{}).Select("user", "age").GroupBy("age").Count(ctx, db)
+	fmt.Println(n, err)
+	sum, err := New(&TestDialect{}).Select("user").Union(New(&TestDialect{}).Select("admin")).Sum(ctx, db, "age")
+	fmt.Println(sum, err)
+	// output:
+	// "select count(*) from 'user' where age > ?" [18]
+	// 5 <nil>
+	// "select count(*) from (select 'age' from 'user' group by age) 't'" []
+	// 5 <nil>
+	// "select sum('age') from (select * from 'user' union select * from 'admin') 't'" []
+	// 5 <nil>
+}
+
+func ExamplePaginate() {
+	ctx := context.Background()
+	db := &aggregateTestDB{total: 5}
+	type User struct {
+		ID int `orm:"id"`
+	}
+	b := New(&TestDialect{}).Select("user").OrderBy("id")
+
+	for _, page := range []int{0, 3, 4} {
+		p, err := Paginate[User](ctx, db, b, page, 2)
+		if err != nil {
+			fmt.Println(err)
+			continue
+		}
+		fmt.Printf("page=%d items=%v total=%d pages=%d next=%v\n", p.Page, p.Items, p.Total, p.TotalPages, p.HasNext)
+	}
+	_, err := Paginate[User](ctx, db, b, 1, 0)
+	fmt.Println(err)
+	// output:
+	// "select count(*) from 'user'" []
+	// "select * from 'user' order by id limit ?,?" [0 2]
+	// page=1 items=[{1} {2}] total=5 pages=3 next=true
+	// "select count(*) from 'user'" []
+	// "select * from 'user' order by id limit ?,?" [4 2]
+	// page=3 items=[{5}] total=5 pages=3 next=false
+	// "select count(*) from 'user'" []
+	// page=4 items=[] total=5 pages=3 next=false
+	// orm: paginate: invalid page size 0
+}
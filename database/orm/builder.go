@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"reflect"
 	"strings"
+	"time"
 )
 
 type Action string
@@ -29,7 +30,27 @@ type Builder struct {
 	groupBy  []string
 	having   Condition
 	distinct bool
+	preloads []string
+	ctes     []cte
+	unions   []union
+	alias    string
+	conflict *Conflict
+	model    interface{}
+	ignores  modelIgnores
+	unscoped bool
+	ctx      context.Context
+	scope    tableScope
+	cacheTTL *time.Duration
 	err      error
+	// modelTable is the table of model, which is parsed once per builder.
+	modelTable *Table
+	// now is the time of the timestamps written by the current build or exec, it's zero between calls.
+	now time.Time
+}
+
+type modelIgnores struct {
+	zeroValue bool
+	columns   []string
 }
 
 func New(dialect ...Dialect) *Builder {
@@ -42,91 +63,210 @@ func New(dialect ...Dialect) *Builder {
 	return b
 }
 
+func (b *Builder) Clone() *Builder {
+	c := *b
+	c.columns = append(Templates{}, b.columns...)
+	c.joins = append(Templates{}, b.joins...)
+	c.where.templates = append([]Template{}, b.where.templates...)
+	c.having.templates = append([]Template{}, b.having.templates...)
+	c.orderBy = append([]string{}, b.orderBy...)
+	c.groupBy = append([]string{}, b.groupBy...)
+	c.preloads = append([]string{}, b.preloads...)
+	c.ctes = append([]cte{}, b.ctes...)
+	c.unions = append([]union{}, b.unions...)
+	c.ignores.columns = append([]string{}, b.ignores.columns...)
+	c.scope.skips = append([]string{}, b.scope.skips...)
+	return &c
+}
+
 func (b *Builder) Build() TemplateWithError {
+	return b.build(b.context())
+}
+
+func (b *Builder) build(ctx context.Context) TemplateWithError {
 	if b.err != nil {
 		return TemplateWithError{Err: b.err}
 	}
+	if b.now.IsZero() {
+		b.now = NowFunc()
+		defer func() { b.now = time.Time{} }()
+	}
+	columns := b.columns
+	if b.model != nil && (b.action == actionInsert || b.action == actionUpdate) {
+		modelColumns, err := b.modelColumns()
+		if err != nil {
+			return TemplateWithError{Err: err}
+		}
+		columns = append(append(Templates{}, modelColumns...), b.columns...)
+	}
+	if err := b.where.Err(); err != nil {
+		return TemplateWithError{Err: err}
+	}
+	if err := b.having.Err(); err != nil {
+		return TemplateWithError{Err: err}
+	}
+	where, err := b.scopedWhere(ctx)
+	if err != nil {
+		return TemplateWithError{Err: err}
+	}
+	if b.action == actionInsert {
+		if columns, err = b.scopedColumns(ctx, columns); err != nil {
+			return TemplateWithError{Err: err}
+		}
+	}
 	t := Template{}
 	switch b.action {
 	case actionSelect:
-		columns := make([]string, 0, len(b.columns.Formats()))
-		for _, c := range b.columns.Formats() {
-			columns = append(columns, b.dialect.Quote(c))
+		if t, err = b.buildSelect(ctx, where); err != nil {
+			return TemplateWithError{Err: err}
 		}
-		if len(columns) == 0 {
-			columns = []string{"*"}
-		}
-		if b.distinct {
-			t = t.Appendf(fmt.Sprintf("select distinct %s from %s",
-				strings.Join(columns, ","),
-				b.dialect.Quote(b.table.Format),
-			), append(append([]interface{}{}, b.columns.Values()...), b.table.Values...))
-		} else {
-			t = t.Appendf(fmt.Sprintf("select %s from %s",
-				strings.Join(columns, ","),
-				b.dialect.Quote(b.table.Format),
-			), b.columns.Values()...)
-		}
-		if len(b.joins) > 0 {
-			t = t.Merge(b.joins.Join(" ", "", ""))
-		}
-		if b.where.IsNotEmpty() {
-			t = t.Appendf(" where ").Merge(b.where.And())
+	case actionInsert:
+		values := make(Templates, 0, len(columns))
+		for _, c := range columns {
+			v, err := columnValue(b.dialect, c)
+			if err != nil {
+				return TemplateWithError{Err: err}
+			}
+			values = append(values, v)
 		}
-		if len(b.groupBy) > 0 {
-			t = t.Appendf(fmt.Sprintf(" group by %s", strings.Join(b.groupBy, ",")))
+		t = t.Appendf(fmt.Sprintf("insert into %s(%s) values",
+			b.table.Format,
+			strings.Join(columns.Formats(), ", "),
+		)).Merge(values.Join(",", "(", ")"))
+		if b.conflict != nil {
+			t = t.Appendf(renderConflict(b.dialect, *b.conflict, columns.Formats()))
 		}
-		if b.having.IsNotEmpty() {
-			t = t.Appendf(" having ").Merge(b.having.And())
+		if where.IsNotEmpty() {
+			t = t.Appendf(" where ").Merge(where.And())
 		}
-		if len(b.orderBy) > 0 {
-			t = t.Appendf(fmt.Sprintf(" order by %s", strings.Join(b.orderBy, ", ")))
+	case actionUpdate:
+		pairs := make(Templates, 0, len(columns))
+		for _, c := range columns {
+			v, err := columnValue(b.dialect, c)
+			if err != nil {
+				return TemplateWithError{Err: err}
+			}
+			pairs = append(pairs, NewTemplate(fmt.Sprintf("%s = ", b.dialect.Quote(c.Format))).Merge(v))
 		}
-		if len(b.paging.Format) > 0 {
-			t = t.Appendf(" ").Merge(b.paging)
+		t = t.Appendf(fmt.Sprintf("update %s set ", b.table.Format)).Merge(pairs.Join(",", "", ""))
+		if where.IsNotEmpty() {
+			t = t.Appendf(" where ").Merge(where.And())
 		}
-	case actionInsert:
-		t = t.Appendf(fmt.Sprintf("insert into %s(%s) values(%s)",
-			b.table,
-			strings.Join(b.columns.Formats(), ", "),
-			strings.Join(repeatString("?", len(b.columns)), ","),
-		), b.columns.Values()...)
-		if b.where.IsNotEmpty() {
-			t = t.Appendf(" where ").Merge(b.where.And())
+	case actionDelete:
+		soft, ok, err := b.softDeleteTemplate()
+		if err != nil {
+			return TemplateWithError{Err: err}
 		}
-	case actionUpdate:
-		pairs := make([]string, 0, len(b.columns))
-		for _, c := range b.columns {
-			pairs = append(pairs, fmt.Sprintf("%s = ?", b.dialect.Quote(c.Format)))
+		if ok {
+			t = soft
+		} else {
+			t = t.Appendf(fmt.Sprintf("delete from %s", b.table.Format))
 		}
-		t.Appendf(fmt.Sprintf("update %s set %s",
-			b.table,
-			strings.Join(pairs, ","),
-		), b.columns.Values()...)
-		if b.where.IsNotEmpty() {
-			t = t.Appendf(" where ").Merge(b.where.And())
+		if where.IsNotEmpty() {
+			t = t.Appendf(" where ").Merge(where.And())
 		}
-	case actionDelete:
-		t = t.Appendf(fmt.Sprintf("delete from %s", b.table))
-		if b.where.IsNotEmpty() {
-			t = t.Appendf(" where ").Merge(b.where.And())
+	}
+	if len(b.ctes) > 0 {
+		with, err := b.buildWith(ctx)
+		if err != nil {
+			return TemplateWithError{Err: err}
 		}
+		t = with.Merge(t)
 	}
 	return TemplateWithError{Template: t}
 }
 
+func (b *Builder) buildSelect(ctx context.Context, where Condition) (Template, error) {
+	columns := make([]string, 0, len(b.columns))
+	for _, c := range b.columns.Formats() {
+		columns = append(columns, quoteSelectColumn(b.dialect, c))
+	}
+	if len(columns) == 0 {
+		columns = []string{"*"}
+	}
+	head := "select "
+	if b.distinct {
+		head = "select distinct "
+	}
+	t := NewTemplate(head+strings.Join(columns, ",")+" from ", b.columns.Values()...).Merge(b.table)
+	if len(b.joins) > 0 {
+		t = t.Merge(b.joins.Join(" ", " ", ""))
+	}
+	if where.IsNotEmpty() {
+		t = t.Appendf(" where ").Merge(where.And())
+	}
+	if len(b.groupBy) > 0 {
+		t = t.Appendf(fmt.Sprintf(" group by %s", strings.Join(b.groupBy, ",")))
+	}
+	if b.having.IsNotEmpty() {
+		t = t.Appendf(" having ").Merge(b.having.And())
+	}
+	for _, u := range b.unions {
+		other := u.builder.build(ctx)
+		if other.Err != nil {
+			return Template{}, other.Err
+		}
+		t = t.Appendf(" " + u.op + " ").Merge(other.Template)
+	}
+	if len(b.orderBy) > 0 {
+		t = t.Appendf(fmt.Sprintf(" order by %s", strings.Join(b.orderBy, ", ")))
+	}
+	if len(b.paging.Format) > 0 {
+		t = t.Appendf(" ").Merge(b.paging)
+	}
+	return t, nil
+}
+
 func (b *Builder) Query(ctx context.Context, db DBTX, i interface{}) error {
 	if b.err != nil {
 		return b.err
 	}
-	return b.Build().Query(ctx, db, i)
+	if b.cacheTTL != nil {
+		ctx = WithCacheTTL(ctx, *b.cacheTTL)
+	}
+	if err := b.build(ctx).Query(ctx, db, i); err != nil {
+		return err
+	}
+	if b.action == actionSelect && len(b.preloads) > 0 {
+		if err := preload(ctx, db, b.dialect, i, b.preloads...); err != nil {
+			return err
+		}
+	}
+	if b.action == actionSelect && b.model != nil {
+		return callAfterFind(ctx, i)
+	}
+	return nil
 }
 
 func (b *Builder) Exec(ctx context.Context, db DBTX) (sql.Result, error) {
 	if b.err != nil {
 		return nil, b.err
 	}
-	return b.Build().Exec(ctx, db)
+	if b.model != nil {
+		if err := callBeforeHook(ctx, b.action, b.model); err != nil {
+			return nil, err
+		}
+	}
+	b.now = NowFunc()
+	defer func() { b.now = time.Time{} }()
+	result, err := b.build(ctx).Exec(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	if b.model != nil {
+		if err := b.checkVersion(result); err != nil {
+			return result, err
+		}
+		if b.action != actionSelect {
+			if err := b.writeTimestamps(); err != nil {
+				return result, err
+			}
+		}
+		if err := callAfterHook(ctx, b.action, b.model); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
 }
 
 func (b *Builder) Dialect(d Dialect) *Builder {
@@ -137,12 +277,18 @@ func (b *Builder) Dialect(d Dialect) *Builder {
 	return b
 }
 
-func (b *Builder) Select(table string, columns ...string) *Builder {
+func (b *Builder) Select(table interface{}, columns ...string) *Builder {
 	if b.err != nil {
 		return b
 	}
+	t, err := tableTemplate(b.dialect, table)
+	if err != nil {
+		b.err = err
+		return b
+	}
 	b.action = actionSelect
-	b.table = NewTemplate(table)
+	b.table = t
+	b.scope = newTableScope(table)
 	for _, c := range columns {
 		b.columns = append(b.columns, NewTemplate(c))
 	}
@@ -164,7 +310,9 @@ func (b *Builder) SelectModel(model interface{}, ignoreColumns ...string) *Build
 		return b
 	}
 	b.action = actionSelect
-	b.table = NewTemplate(ParseTableName(model))
+	b.setTable(ParseTableName(model))
+	b.model = model
+	b.modelTable = t
 	for _, name := range names {
 		if containStrings(ignoreColumns, name) {
 			continue
@@ -179,34 +327,93 @@ func (b *Builder) Insert(table string, columns map[string]interface{}) *Builder
 		return b
 	}
 	b.action = actionInsert
-	b.table = NewTemplate(table)
-	for name, value := range columns {
-		b.columns = append(b.columns, NewTemplate(name, value))
+	b.setTable(table)
+	for _, name := range sortedKeys(columns) {
+		b.columns = append(b.columns, NewTemplate(name, columns[name]))
 	}
 	return b
 }
 
+// InsertModel inserts the columns of model, except the zero values if ignoreZeroValue,
+// and the ignoreColumns, which are column names or field names.
 func (b *Builder) InsertModel(model interface{}, ignoreZeroValue bool, ignoreColumns ...string) *Builder {
 	if b.err != nil {
 		return b
 	}
-	b.action = actionInsert
-	b.table = NewTemplate(ParseTableName(model))
-	pairs, err := ParseColumnValuePairs(b.dialect, model)
-	if err != nil {
+	if err := b.setModel(model); err != nil {
 		b.err = err
 		return b
 	}
+	b.action = actionInsert
+	b.setTable(ParseTableName(model))
+	b.ignores = modelIgnores{zeroValue: ignoreZeroValue, columns: ignoreColumns}
+	return b
+}
+
+// setTable sets the quoted table of every action, only Select accepts aliases and subqueries.
+func (b *Builder) setTable(name string) {
+	b.table = NewTemplate(b.dialect.Quote(name))
+	b.scope = tableScope{table: name}
+}
+
+func (b *Builder) setModel(model interface{}) error {
+	t, err := ParseTable(b.dialect, model)
+	if err != nil {
+		return err
+	}
+	b.model = model
+	b.modelTable = t
+	return nil
+}
+
+func (b *Builder) modelColumns() (Templates, error) {
+	pairs, err := ParseColumnValuePairs(b.dialect, b.model)
+	if err != nil {
+		return nil, err
+	}
+	if pairs, err = b.fillTimestamps(pairs); err != nil {
+		return nil, err
+	}
+	if pairs, err = b.fillVersion(pairs); err != nil {
+		return nil, err
+	}
+	version, _, err := b.versionColumn()
+	if err != nil {
+		return nil, err
+	}
+	ignored, err := b.ignoredColumns()
+	if err != nil {
+		return nil, err
+	}
+	var result Templates
 	for _, pair := range pairs {
-		if ignoreZeroValue && reflect.ValueOf(pair.Value).IsZero() {
+		if pair.Column != version && b.ignores.zeroValue && (pair.Value == nil || reflect.ValueOf(pair.Value).IsZero()) {
 			continue
 		}
-		if containStrings(ignoreColumns, pair.Column) {
+		if pair.Column != version && containStrings(ignored, pair.Column) {
 			continue
 		}
-		b.columns = append(b.columns, NewTemplate(pair.Column, pair.Value))
+		result = append(result, NewTemplate(pair.Column, pair.Value))
 	}
-	return b
+	return result, nil
+}
+
+// ignoredColumns returns the columns of the ignores, which may be column names or field names of the model.
+func (b *Builder) ignoredColumns() ([]string, error) {
+	if len(b.ignores.columns) == 0 {
+		return nil, nil
+	}
+	fields, err := parseFields(b.dialect, unrefValue(reflect.ValueOf(b.model)).Type())
+	if err != nil {
+		return nil, err
+	}
+	result := append([]string{}, b.ignores.columns...)
+	for _, f := range fields {
+		if containStrings(b.ignores.columns, f.sf.Name) {
+			result = append(result, f.column.Name)
+		}
+	}
+	return result, nil
 }
 
 func (b *Builder) Update(table string, columns map[string]interface{}) *Builder {
@@ -214,33 +421,26 @@ func (b *Builder) Update(table string, columns map[string]interface{}) *Builder
 		return b
 	}
 	b.action = actionUpdate
-	b.table = NewTemplate(table)
-	for name, value := range columns {
-		b.columns = append(b.columns, NewTemplate(name, value))
+	b.setTable(table)
+	for _, name := range sortedKeys(columns) {
+		b.columns = append(b.columns, NewTemplate(name, columns[name]))
 	}
 	return b
 }
 
-func (b *Builder) UpdateModel(model interface{}, ignoreZeroValue bool, ignoreFields ...string) *Builder {
+// UpdateModel updates the columns of model, except the zero values if ignoreZeroValue,
+// and the ignoreColumns, which are column names or field names.
+func (b *Builder) UpdateModel(model interface{}, ignoreZeroValue bool, ignoreColumns ...string) *Builder {
 	if b.err != nil {
 		return b
 	}
-	b.action = actionUpdate
-	b.table = NewTemplate(ParseTableName(model))
-	m, err := mapStructToMap(model)
-	if err != nil {
+	if err := b.setModel(model); err != nil {
 		b.err = err
 		return b
 	}
-	for name, value := range m {
-		if ignoreZeroValue && reflect.ValueOf(value).IsZero() {
-			continue
-		}
-		if containStrings(ignoreFields, name) {
-			continue
-		}
-		b.columns = append(b.columns, NewTemplate(name, value))
-	}
+	b.action = actionUpdate
+	b.setTable(ParseTableName(model))
+	b.ignores = modelIgnores{zeroValue: ignoreZeroValue, columns: ignoreColumns}
 	return b
 }
 
@@ -249,7 +449,7 @@ func (b *Builder) Delete(table string) *Builder {
 		return b
 	}
 	b.action = actionDelete
-	b.table = NewTemplate(table)
+	b.setTable(table)
 	return b
 }
 
@@ -268,15 +468,27 @@ func (b *Builder) WhereIn(column string, values ...interface{}) *Builder {
 	if len(values) == 0 {
 		values = append(values, "null")
 	}
-	holders := make([]string, len(values))
-	for i := 0; i < len(values); i++ {
-		holders = append(holders, "?")
-	}
+	holders := repeatString("?", len(values))
 	format := fmt.Sprintf("%s in (%s)", b.dialect.Quote(column), strings.Join(holders, ", "))
 	b.where.Appendf(format, values...)
 	return b
 }
 
+func (b *Builder) WhereExpr(exprs ...Expr) *Builder {
+	if b.err != nil {
+		return b
+	}
+	t := And(exprs...).Build(b.dialect)
+	if t.Err != nil {
+		b.err = t.Err
+		return b
+	}
+	if !t.IsEmpty() {
+		b.where.Append(t.Template.Bracket())
+	}
+	return b
+}
+
 func (b *Builder) WhereTemplate(tt ...Template) *Builder {
 	if b.err != nil {
 		return b
@@ -301,6 +513,47 @@ func (b *Builder) Paging(page, size int) *Builder {
 	return b
 }
 
+func (b *Builder) Preload(paths ...string) *Builder {
+	if b.err != nil {
+		return b
+	}
+	b.preloads = append(b.preloads, paths...)
+	return b
+}
+
+// Keyset selects size rows ordered by column after the column value after, which is nil for the first page,
+// column may end with " desc" to select the rows before after in descending order.
+func (b *Builder) Keyset(column string, after interface{}, size int) *Builder {
+	if b.err != nil {
+		return b
+	}
+	name, desc := parseKeysetColumn(column)
+	quoted := b.dialect.Quote(name)
+	op, order := ">", quoted
+	if desc {
+		op, order = "<", quoted+" desc"
+	}
+	if after != nil {
+		b.where.Appendf(fmt.Sprintf("%s %s ?", quoted, op), after)
+	}
+	b.orderBy = append([]string{order}, b.orderBy...)
+	b.paging = NewTemplate("limit ?", size)
+	return b
+}
+
+func parseKeysetColumn(column string) (name string, desc bool) {
+	fields := strings.Fields(column)
+	if len(fields) == 2 {
+		switch strings.ToLower(fields[1]) {
+		case "asc":
+			return fields[0], false
+		case "desc":
+			return fields[0], true
+		}
+	}
+	return column, false
+}
+
 func (b *Builder) GroupBy(fields ...string) *Builder {
 	if b.err != nil {
 		return b
@@ -316,3 +569,18 @@ func (b *Builder) Having(format string, values ...interface{}) *Builder {
 	b.having.Appendf(format, values...)
 	return b
 }
+
+func (b *Builder) HavingExpr(exprs ...Expr) *Builder {
+	if b.err != nil {
+		return b
+	}
+	t := And(exprs...).Build(b.dialect)
+	if t.Err != nil {
+		b.err = t.Err
+		return b
+	}
+	if !t.IsEmpty() {
+		b.having.Append(t.Template.Bracket())
+	}
+	return b
+}
@@ -0,0 +1,32 @@
+package orm
+
+import "fmt"
+
+func ExampleBuilder_UpdateModel() {
+	type User struct {
+		ID   int    `orm:"id"`
+		Name string `orm:"name"`
+		Age  int    `orm:"age"`
+	}
+	t := New(&TestDialect{}).
+		UpdateModel(User{ID: 1, Name: "Medivh"}, true, "id").
+		Where("id = ?", 1).
+		Build()
+	fmt.Println(t)
+	// output:
+	// "update 'User' set 'name' = ? where id = ?": []interface {}{"Medivh", 1}
+}
+
+func ExampleBuilder_UpdateModel_ignoreFields() {
+	type User struct {
+		ID        int    `orm:"id"`
+		FirstName string `orm:"first_name"`
+		Age       int    `orm:"age"`
+	}
+	u := User{ID: 1, FirstName: "Medivh", Age: 18}
+	fmt.Println(New(&TestDialect{}).UpdateModel(u, false, "ID", "Age").Where("id = ?", 1).Build())
+	fmt.Println(New(&TestDialect{}).UpdateModel(u, false, "id", "first_name").Where("id = ?", 1).Build())
+	// output:
+	// "update 'User' set 'first_name' = ? where id = ?": []interface {}{"Medivh", 1}
+	// "update 'User' set 'age' = ? where id = ?": []interface {}{18, 1}
+}
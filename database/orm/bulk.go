@@ -1,12 +1,32 @@
 package orm
 
 import (
+	"context"
 	"fmt"
 	"reflect"
 	"strings"
 )
 
-func BulkInsert(dialect Dialect, models interface{}) TemplateWithError {
+const DefaultMaxParams = 999
+
+type MaxParamsDialect interface {
+	Dialect
+	MaxParams() int
+}
+
+func MaxParams(dialect Dialect) int {
+	if d, ok := dialect.(MaxParamsDialect); ok && d.MaxParams() > 0 {
+		return d.MaxParams()
+	}
+	return DefaultMaxParams
+}
+
+// BulkInsert builds a single insert statement of all models, which is not chunked by MaxParams,
+// use ExecBulkInsert for slices that may exceed the parameter limit of the dialect.
+func BulkInsert(dialect Dialect, models interface{}, conflict ...Conflict) TemplateWithError {
+	if dialect == nil {
+		dialect = GetDefaultDialect()
+	}
 	value := reflect.ValueOf(models)
 	for value.Kind() == reflect.Ptr {
 		value = value.Elem()
@@ -14,12 +34,19 @@ func BulkInsert(dialect Dialect, models interface{}) TemplateWithError {
 	if value.Kind() != reflect.Slice {
 		return TemplateWithError{Err: ErrRequireSliceType}
 	}
-	l := value.Len()
-	if l == 0 {
+	var c *Conflict
+	if len(conflict) > 0 {
+		c = &conflict[0]
+	}
+	return bulkInsert(dialect, value, 0, value.Len(), c)
+}
+
+func bulkInsert(dialect Dialect, value reflect.Value, start, end int, conflict *Conflict) TemplateWithError {
+	if end <= start {
 		return TemplateWithError{}
 	}
 	var matrix [][]ColumnValuePair
-	for i := 0; i < value.Len(); i++ {
+	for i := start; i < end; i++ {
 		elem := value.Index(i)
 		pairs, err := ParseColumnValuePairs(dialect, elem.Interface())
 		if err != nil {
@@ -32,7 +59,7 @@ func BulkInsert(dialect Dialect, models interface{}) TemplateWithError {
 	if len(matrix) == 0 {
 		return TemplateWithError{}
 	}
-	table := ParseTableName(value.Index(0).Interface())
+	table := ParseTableName(value.Index(start).Interface())
 	columns := make([]string, 0, len(matrix[0]))
 	for _, c := range matrix[0] {
 		columns = append(columns, c.Column)
@@ -48,9 +75,91 @@ func BulkInsert(dialect Dialect, models interface{}) TemplateWithError {
 		}
 	}
 	t := NewTemplate(fmt.Sprintf("insert into %s(%s) values %s",
-		table,
+		dialect.Quote(table),
 		strings.Join(columns, ", "),
 		strings.Join(holders, ", "),
 	), values...)
+	if conflict != nil {
+		t = t.Appendf(renderConflict(dialect, *conflict, columns))
+	}
 	return TemplateWithError{Template: t}
 }
+
+type BulkInsertOptions struct {
+	// ChunkSize is the number of rows per statement,
+	// defaults to the dialect max params divided by the number of columns.
+	ChunkSize int
+	Conflict  *Conflict
+	// Prepared reuses a prepared statement for chunks with the same shape,
+	// from the statement cache of the db if any, or else prepared on the transaction.
+	Prepared bool
+	Progress func(inserted, total int)
+}
+
+// ExecBulkInsert inserts models in chunks within a transaction, and returns the number of affected rows.
+func ExecBulkInsert(ctx context.Context, db DBTX, dialect Dialect, models interface{}, options ...BulkInsertOptions) (int64, error) {
+	if dialect == nil {
+		dialect = GetDefaultDialect()
+	}
+	var opts BulkInsertOptions
+	if len(options) > 0 {
+		opts = options[0]
+	}
+	value := reflect.ValueOf(models)
+	for value.Kind() == reflect.Ptr {
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Slice {
+		return 0, ErrRequireSliceType
+	}
+	total := value.Len()
+	if total == 0 {
+		return 0, nil
+	}
+	size := opts.ChunkSize
+	if size <= 0 {
+		pairs, err := ParseColumnValuePairs(dialect, value.Index(0).Interface())
+		if err != nil {
+			return 0, err
+		}
+		size = total
+		if len(pairs) > 0 {
+			size = MaxParams(dialect) / len(pairs)
+		}
+		if size <= 0 {
+			return 0, errorf("bulk insert: too many columns for dialect max params %d", MaxParams(dialect))
+		}
+	}
+
+	var affected int64
+	err := db.Tx(ctx, func(ctx context.Context, tx DBTX) error {
+		if opts.Prepared {
+			ctx = withTxStmts(ctx)
+		}
+		for start := 0; start < total; start += size {
+			end := start + size
+			if end > total {
+				end = total
+			}
+			t := bulkInsert(dialect, value, start, end, opts.Conflict)
+			if t.Err != nil {
+				return t.Err
+			}
+			result, err := tx.Exec(ctx, t.Template)
+			if err != nil {
+				return err
+			}
+			if n, err := result.RowsAffected(); err == nil {
+				affected += n
+			}
+			if opts.Progress != nil {
+				opts.Progress(end, total)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return affected, nil
+}
@@ -1,8 +1,11 @@
 package orm
 
 import (
+	"context"
+	"database/sql"
 	"fmt"
 	"reflect"
+	"testing"
 )
 
 func ExampleBulkInsert() {
@@ -17,7 +20,7 @@ func ExampleBulkInsert() {
 	})
 	fmt.Println(t)
 	// output:
-	// "insert into User(name, age) values (?,?), (?,?), (?,?)": []interface {}{"Medivh", 18, "Jason", 22, "Mike", 30}
+	// "insert into 'User'(name, age) values (?,?), (?,?), (?,?)": []interface {}{"Medivh", 18, "Jason", 22, "Mike", 30}
 }
 
 type TestDialect struct{}
@@ -47,3 +50,141 @@ func (d *TestDialect) MappingType(rt reflect.Type) string {
 func (d *TestDialect) Quote(s string) string {
 	return fmt.Sprintf("'%s'", s)
 }
+
+type maxParamsTestDialect struct {
+	sqliteTestDialect
+	max int
+}
+
+func (d *maxParamsTestDialect) MaxParams() int {
+	return d.max
+}
+
+// bulkTestDB records the statements executed in its transactions, and the number of statements prepared on them.
+type bulkTestDB struct {
+	DBTX
+	statements *[]string
+	prepared   *int
+}
+
+func (db *bulkTestDB) Tx(ctx context.Context, fn func(ctx context.Context, tx DBTX) error, opts ...*sql.TxOptions) error {
+	return db.DBTX.Tx(ctx, func(ctx context.Context, tx DBTX) error {
+		return fn(ctx, &bulkTestDB{DBTX: tx, statements: db.statements, prepared: db.prepared})
+	}, opts...)
+}
+
+func (db *bulkTestDB) Exec(ctx context.Context, t Template) (sql.Result, error) {
+	*db.statements = append(*db.statements, "exec "+t.Format)
+	result, err := db.DBTX.Exec(ctx, t)
+	if stmts, ok := ctx.Value(txStmtsKey{}).(*txStmts); ok {
+		*db.prepared = len(stmts.items)
+	}
+	return result, err
+}
+
+func TestExecBulkInsert(t *testing.T) {
+	type User struct {
+		Name string `orm:"name"`
+		Age  int    `orm:"age"`
+	}
+	users := []User{{"a", 1}, {"b", 2}, {"c", 3}, {"d", 4}, {"e", 5}}
+	dialect := &maxParamsTestDialect{max: 5}
+	const (
+		two = `insert into "User"(name, age) values (?,?), (?,?)`
+		one = `insert into "User"(name, age) values (?,?)`
+	)
+	tests := []struct {
+		name       string
+		options    BulkInsertOptions
+		statements []string
+		prepared   int
+		progress   []int
+	}{
+		{"max params", BulkInsertOptions{}, []string{"exec " + two, "exec " + two, "exec " + one}, 0, []int{2, 4, 5}},
+		{"chunk size", BulkInsertOptions{ChunkSize: 4}, []string{"exec " + two + ", (?,?), (?,?)", "exec " + one}, 0, []int{4, 5}},
+		{"prepared", BulkInsertOptions{Prepared: true}, []string{"exec " + two, "exec " + two, "exec " + one}, 2, []int{2, 4, 5}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			raw, _ := openTestDB(t, `create table User (name text, age integer)`)
+			var (
+				statements []string
+				prepared   int
+			)
+			db := &bulkTestDB{DBTX: NewDB(dialect, raw), statements: &statements, prepared: &prepared}
+			var progress []int
+			tt.options.Progress = func(inserted, total int) {
+				if total != len(users) {
+					t.Errorf("got total %d, want %d", total, len(users))
+				}
+				progress = append(progress, inserted)
+			}
+			n, err := ExecBulkInsert(context.Background(), db, dialect, users, tt.options)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if n != int64(len(users)) {
+				t.Errorf("got %d affected, want %d", n, len(users))
+			}
+			if !reflect.DeepEqual(statements, tt.statements) {
+				t.Errorf("got statements %q, want %q", statements, tt.statements)
+			}
+			if prepared != tt.prepared {
+				t.Errorf("got %d prepared statements, want %d", prepared, tt.prepared)
+			}
+			if !reflect.DeepEqual(progress, tt.progress) {
+				t.Errorf("got progress %v, want %v", progress, tt.progress)
+			}
+			var count int
+			if err := raw.QueryRow(`select count(*) from User`).Scan(&count); err != nil {
+				t.Fatal(err)
+			}
+			if count != len(users) {
+				t.Errorf("got %d rows, want %d", count, len(users))
+			}
+		})
+	}
+}
+
+func TestExecBulkInsertPreparedStmtCache(t *testing.T) {
+	type User struct {
+		Name string `orm:"name"`
+		Age  int    `orm:"age"`
+	}
+	users := []User{{"a", 1}, {"b", 2}, {"c", 3}, {"d", 4}, {"e", 5}}
+	raw, _ := openTestDB(t, `create table User (name text, age integer)`)
+	dialect := &maxParamsTestDialect{max: 5}
+	cached, err := WithStmtCache(NewDB(dialect, raw), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var events []string
+	hooked := WithHooks(cached, HookFunc(func(ctx context.Context, e *QueryEvent) {
+		events = append(events, fmt.Sprintf("%s %d", e.Action, e.RowsAffected))
+	}))
+	n, err := ExecBulkInsert(context.Background(), hooked, dialect, users, BulkInsertOptions{Prepared: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != int64(len(users)) {
+		t.Errorf("got %d affected, want %d", n, len(users))
+	}
+	if want := []string{"exec 2", "exec 2", "exec 1"}; !reflect.DeepEqual(events, want) {
+		t.Errorf("got hook events %q, want %q", events, want)
+	}
+	if got := cached.(*db).stmts.lru.Len(); got != 2 {
+		t.Errorf("got %d cached statements, want 2", got)
+	}
+}
+
+func TestExecBulkInsertTooManyColumns(t *testing.T) {
+	type User struct {
+		Name string `orm:"name"`
+		Age  int    `orm:"age"`
+	}
+	raw, _ := openTestDB(t, `create table User (name text, age integer)`)
+	dialect := &maxParamsTestDialect{max: 1}
+	if _, err := ExecBulkInsert(context.Background(), NewDB(dialect, raw), dialect, []User{{"a", 1}}); err == nil {
+		t.Fatal("expected error")
+	}
+}
@@ -0,0 +1,416 @@
+package orm
+
+import (
+	"bytes"
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/gob"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Cache stores encoded query results by key, with tags to invalidate them.
+type Cache interface {
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration, tags ...string) error
+	Invalidate(ctx context.Context, tags ...string) error
+}
+
+type CacheOptions struct {
+	// TTL is used by queries cached without a ttl, defaults to 1 minute.
+	TTL time.Duration
+	// Tags returns the tags of a query or exec, defaults to the lower-cased table names in the statement.
+	Tags func(t Template) []string
+}
+
+func init() {
+	gob.Register(time.Time{})
+}
+
+type cacheTTLKey struct{}
+
+// WithCacheTTL returns a context that makes a db wrapped by WithCache cache its queries for ttl,
+// a non-positive ttl uses CacheOptions.TTL.
+func WithCacheTTL(ctx context.Context, ttl time.Duration) context.Context {
+	return context.WithValue(ctx, cacheTTLKey{}, ttl)
+}
+
+func cacheTTL(ctx context.Context) (time.Duration, bool) {
+	ttl, ok := ctx.Value(cacheTTLKey{}).(time.Duration)
+	return ttl, ok
+}
+
+// Cache makes Query read through the cache of a db wrapped by WithCache.
+func (b *Builder) Cache(ttl time.Duration) *Builder {
+	if b.err != nil {
+		return b
+	}
+	b.cacheTTL = &ttl
+	return b
+}
+
+type cachedDB struct {
+	DBTX
+	cache    Cache
+	options  CacheOptions
+	group    *flightGroup
+	versions *tagVersions
+	pending  *[]string
+}
+
+// WithCache wraps db so that queries marked by Builder.Cache or WithCacheTTL are served from cache,
+// concurrent identical queries run once, and execs invalidate the cached queries of the tables they touch.
+func WithCache(db DBTX, cache Cache, options ...CacheOptions) DBTX {
+	var opts CacheOptions
+	if len(options) > 0 {
+		opts = options[0]
+	}
+	if opts.TTL <= 0 {
+		opts.TTL = time.Minute
+	}
+	if opts.Tags == nil {
+		opts.Tags = TableTags
+	}
+	return &cachedDB{
+		DBTX:     db,
+		cache:    cache,
+		options:  opts,
+		group:    &flightGroup{calls: map[string]*flightCall{}},
+		versions: &tagVersions{m: map[string]uint64{}},
+	}
+}
+
+var tableTagPattern = regexp.MustCompile("(?i)\\b(?:from|join|into|update)\\s+([`\"'\\[]?[\\w.]+[`\"'\\]]?)")
+
+// TableTags returns the lower-cased names of the tables that t reads or writes.
+func TableTags(t Template) []string {
+	var tags []string
+	for _, m := range tableTagPattern.FindAllStringSubmatch(t.Format, -1) {
+		tag := strings.ToLower(strings.Trim(m[1], "`\"'[]"))
+		if tag == "select" || containStrings(tags, tag) {
+			continue
+		}
+		tags = append(tags, tag)
+	}
+	return tags
+}
+
+// cacheKey hashes the format and the values of t as the driver receives them,
+// so pointers and valuers are keyed by what they point to or return rather than by address.
+func cacheKey(t Template) string {
+	h := sha256.New()
+	h.Write([]byte(t.Format))
+	for _, v := range t.Values {
+		if converted, err := driver.DefaultParameterConverter.ConvertValue(v); err == nil {
+			v = converted
+		}
+		fmt.Fprintf(h, "\x00%T:%#v", v, v)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+type cachedRows struct {
+	Columns []string
+	Values  [][]interface{}
+}
+
+func (db *cachedDB) Dialect() Dialect {
+	return DialectOf(db.DBTX)
+}
+
+func (db *cachedDB) Unwrap() DBTX {
+	return db.DBTX
+}
+
+func (db *cachedDB) Query(ctx context.Context, t Template, i interface{}) error {
+	rows, err := db.QueryRows(ctx, t)
+	if err != nil {
+		return err
+	}
+	return rows.Bind(i)
+}
+
+func (db *cachedDB) QueryRows(ctx context.Context, t Template) (*Rows, error) {
+	ttl, ok := cacheTTL(ctx)
+	if !ok || db.pending != nil {
+		return QueryRows(ctx, db.DBTX, t)
+	}
+	if ttl <= 0 {
+		ttl = db.options.TTL
+	}
+	key := cacheKey(t)
+	b, ok, err := db.cache.Get(ctx, key)
+	if err != nil {
+		debugf("cache: get failed: %v", err)
+	}
+	if !ok {
+		b, err = db.group.do(ctx, key, func(ctx context.Context) ([]byte, error) {
+			return db.load(ctx, t, key, ttl)
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	var cached cachedRows
+	if err := gob.NewDecoder(bytes.NewReader(b)).Decode(&cached); err != nil {
+		return nil, err
+	}
+	return NewValueRows(DialectOf(db.DBTX), cached.Columns, cached.Values)
+}
+
+// load queries t and caches the result, unless a write to its tags has started or ended meanwhile,
+// which may have made the result stale. The versions are local to the process.
+func (db *cachedDB) load(ctx context.Context, t Template, key string, ttl time.Duration) ([]byte, error) {
+	tags := db.options.Tags(t)
+	version := db.versions.get(tags)
+	rows, err := QueryRows(ctx, db.DBTX, t)
+	if err != nil {
+		return nil, err
+	}
+	columns, values, err := rows.readAll()
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(cachedRows{Columns: columns, Values: values}); err != nil {
+		return nil, err
+	}
+	if db.versions.get(tags) != version {
+		debugf("cache: skip set of stale result")
+		return buf.Bytes(), nil
+	}
+	if err := db.cache.Set(ctx, key, buf.Bytes(), ttl, tags...); err != nil {
+		debugf("cache: set failed: %v", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func (db *cachedDB) Exec(ctx context.Context, t Template) (sql.Result, error) {
+	tags := db.options.Tags(t)
+	db.versions.incr(tags)
+	result, err := db.DBTX.Exec(ctx, t)
+	if len(tags) > 0 {
+		if db.pending != nil {
+			*db.pending = append(*db.pending, tags...)
+		}
+		db.invalidatePending(ctx, tags)
+	}
+	return result, err
+}
+
+func (db *cachedDB) Tx(ctx context.Context, fn func(ctx context.Context, tx DBTX) error, opts ...*sql.TxOptions) error {
+	var pending []string
+	err := db.DBTX.Tx(ctx, func(ctx context.Context, tx DBTX) error {
+		return fn(ctx, db.withTx(tx, &pending))
+	}, opts...)
+	db.invalidatePending(ctx, pending)
+	return err
+}
+
+func (db *cachedDB) BeginTx(ctx context.Context, opts ...*sql.TxOptions) (DBTX, error) {
+	tx, err := db.DBTX.BeginTx(ctx, opts...)
+	if err != nil {
+		return nil, err
+	}
+	pending := db.pending
+	if pending == nil {
+		pending = &[]string{}
+	}
+	return db.withTx(tx, pending), nil
+}
+
+// withTx wraps tx whose queries bypass the cache, and whose execs invalidate the cache again on commit.
+func (db *cachedDB) withTx(tx DBTX, pending *[]string) *cachedDB {
+	return &cachedDB{DBTX: tx, cache: db.cache, options: db.options, group: db.group, versions: db.versions, pending: pending}
+}
+
+func (db *cachedDB) Commit() error {
+	if err := db.DBTX.Commit(); err != nil {
+		return err
+	}
+	if db.pending != nil {
+		db.invalidatePending(context.Background(), *db.pending)
+	}
+	return nil
+}
+
+// invalidatePending bumps the versions of tags and invalidates them after a write.
+func (db *cachedDB) invalidatePending(ctx context.Context, tags []string) {
+	if len(tags) == 0 {
+		return
+	}
+	db.versions.incr(tags)
+	if err := db.cache.Invalidate(ctx, tags...); err != nil {
+		debugf("cache: invalidate failed: %v", err)
+	}
+}
+
+func (db *cachedDB) Prepare(ctx context.Context, format string) (*sql.Stmt, error) {
+	p, ok := db.DBTX.(Preparer)
+	if !ok {
+		return nil, errorf("prepare: unsupported db type")
+	}
+	return p.Prepare(ctx, format)
+}
+
+type flightGroup struct {
+	mu    sync.Mutex
+	calls map[string]*flightCall
+}
+
+type flightCall struct {
+	done chan struct{}
+	val  []byte
+	err  error
+}
+
+// do runs fn once for concurrent calls of key, with a context detached from the callers' cancellation,
+// and every caller stops waiting when its own ctx is done.
+func (g *flightGroup) do(ctx context.Context, key string, fn func(ctx context.Context) ([]byte, error)) ([]byte, error) {
+	g.mu.Lock()
+	c, ok := g.calls[key]
+	if !ok {
+		c = &flightCall{done: make(chan struct{})}
+		g.calls[key] = c
+		go func() {
+			c.val, c.err = fn(detachedContext{ctx})
+			g.mu.Lock()
+			delete(g.calls, key)
+			g.mu.Unlock()
+			close(c.done)
+		}()
+	}
+	g.mu.Unlock()
+
+	select {
+	case <-c.done:
+		return c.val, c.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// detachedContext keeps the values of a context but never gets canceled.
+type detachedContext struct {
+	parent context.Context
+}
+
+func (c detachedContext) Deadline() (time.Time, bool)       { return time.Time{}, false }
+func (c detachedContext) Done() <-chan struct{}             { return nil }
+func (c detachedContext) Err() error                        { return nil }
+func (c detachedContext) Value(key interface{}) interface{} { return c.parent.Value(key) }
+
+// tagVersions counts the writes of tags, a query result is cached only if its tags were not written while loading.
+type tagVersions struct {
+	mu sync.Mutex
+	m  map[string]uint64
+}
+
+func (v *tagVersions) get(tags []string) uint64 {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	var sum uint64
+	for _, tag := range tags {
+		sum += v.m[tag]
+	}
+	return sum
+}
+
+func (v *tagVersions) incr(tags []string) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	for _, tag := range tags {
+		v.m[tag]++
+	}
+}
+
+type lruCache struct {
+	mu    sync.Mutex
+	size  int
+	items map[string]*list.Element
+	lru   *list.List
+	tags  map[string]map[string]struct{}
+}
+
+type lruEntry struct {
+	key      string
+	value    []byte
+	expireAt time.Time
+	tags     []string
+}
+
+// NewLRUCache returns an in-memory Cache keeping at most size entries.
+func NewLRUCache(size int) Cache {
+	if size <= 0 {
+		size = 1024
+	}
+	return &lruCache{size: size, items: map[string]*list.Element{}, lru: list.New(), tags: map[string]map[string]struct{}{}}
+}
+
+func (c *lruCache) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.items[key]
+	if !ok {
+		return nil, false, nil
+	}
+	entry := e.Value.(*lruEntry)
+	if time.Now().After(entry.expireAt) {
+		c.remove(e)
+		return nil, false, nil
+	}
+	c.lru.MoveToFront(e)
+	return entry.value, true, nil
+}
+
+func (c *lruCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration, tags ...string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.items[key]; ok {
+		c.remove(e)
+	}
+	entry := &lruEntry{key: key, value: value, expireAt: time.Now().Add(ttl), tags: tags}
+	c.items[key] = c.lru.PushFront(entry)
+	for _, tag := range tags {
+		if c.tags[tag] == nil {
+			c.tags[tag] = map[string]struct{}{}
+		}
+		c.tags[tag][key] = struct{}{}
+	}
+	for c.lru.Len() > c.size {
+		c.remove(c.lru.Back())
+	}
+	return nil
+}
+
+func (c *lruCache) Invalidate(ctx context.Context, tags ...string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, tag := range tags {
+		for key := range c.tags[tag] {
+			if e, ok := c.items[key]; ok {
+				c.remove(e)
+			}
+		}
+		delete(c.tags, tag)
+	}
+	return nil
+}
+
+func (c *lruCache) remove(e *list.Element) {
+	entry := c.lru.Remove(e).(*lruEntry)
+	delete(c.items, entry.key)
+	for _, tag := range entry.tags {
+		delete(c.tags[tag], entry.key)
+		if len(c.tags[tag]) == 0 {
+			delete(c.tags, tag)
+		}
+	}
+}
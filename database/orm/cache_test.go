@@ -0,0 +1,175 @@
+package orm
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func ExampleTableTags() {
+	fmt.Println(TableTags(NewTemplate(`select * from "user" u join role r on r.id = u.role_id where u.id in (select user_id from Log)`)))
+	fmt.Println(TableTags(NewTemplate("insert into user(name) values(?)", "Medivh")))
+	fmt.Println(TableTags(NewTemplate("update `user` set name = ?", "Jason")))
+	// output:
+	// [user role log]
+	// [user]
+	// [user]
+}
+
+func ExampleNewLRUCache() {
+	ctx := context.Background()
+	c := NewLRUCache(2)
+	c.Set(ctx, "a", []byte("1"), time.Minute, "user")
+	c.Set(ctx, "b", []byte("2"), time.Minute, "role")
+	c.Set(ctx, "c", []byte("3"), time.Minute, "user", "role")
+	c.Invalidate(ctx, "role")
+	for _, key := range []string{"a", "b", "c"} {
+		_, ok, _ := c.Get(ctx, key)
+		fmt.Println(key, ok)
+	}
+	// output:
+	// a false
+	// b false
+	// c false
+}
+
+// blockingDB answers queries with a single row once release is closed.
+type blockingDB struct {
+	started chan struct{}
+	release chan struct{}
+	queries int32
+}
+
+func (db *blockingDB) Query(ctx context.Context, t Template, i interface{}) error {
+	rows, err := db.QueryRows(ctx, t)
+	if err != nil {
+		return err
+	}
+	return rows.Bind(i)
+}
+
+func (db *blockingDB) QueryRows(ctx context.Context, t Template) (*Rows, error) {
+	atomic.AddInt32(&db.queries, 1)
+	db.started <- struct{}{}
+	select {
+	case <-db.release:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	return NewValueRows(&TestDialect{}, []string{"name"}, [][]interface{}{{"Medivh"}})
+}
+
+func (db *blockingDB) Exec(ctx context.Context, t Template) (sql.Result, error) {
+	return nil, nil
+}
+
+func (db *blockingDB) Tx(ctx context.Context, fn func(ctx context.Context, tx DBTX) error, opts ...*sql.TxOptions) error {
+	return fn(ctx, db)
+}
+
+func (db *blockingDB) BeginTx(ctx context.Context, opts ...*sql.TxOptions) (DBTX, error) {
+	return db, nil
+}
+
+func (db *blockingDB) Dialect() Dialect { return &TestDialect{} }
+func (db *blockingDB) Rollback() error  { return nil }
+func (db *blockingDB) Commit() error    { return nil }
+
+func TestCacheSkipsResultLoadedDuringWrite(t *testing.T) {
+	raw := &blockingDB{started: make(chan struct{}, 1), release: make(chan struct{})}
+	cache := NewLRUCache(16)
+	db := WithCache(raw, cache)
+	ctx := WithCacheTTL(context.Background(), time.Minute)
+	query := NewTemplate("select name from user")
+
+	done := make(chan error)
+	go func() {
+		var names []string
+		done <- db.Query(ctx, query, &names)
+	}()
+	<-raw.started
+	if _, err := db.Exec(ctx, NewTemplate("update user set name = ?", "Jason")); err != nil {
+		t.Fatal(err)
+	}
+	close(raw.release)
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	if _, ok, _ := cache.Get(ctx, cacheKey(query)); ok {
+		t.Fatal("result loaded before the write was cached")
+	}
+
+	var names []string
+	go func() { <-raw.started }()
+	if err := db.Query(ctx, query, &names); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok, _ := cache.Get(ctx, cacheKey(query)); !ok {
+		t.Fatal("result not cached")
+	}
+}
+
+func TestCacheSharedLoadIgnoresCallerCancel(t *testing.T) {
+	raw := &blockingDB{started: make(chan struct{}, 1), release: make(chan struct{})}
+	db := WithCache(raw, NewLRUCache(16))
+	query := NewTemplate("select name from user")
+
+	canceled, cancel := context.WithCancel(WithCacheTTL(context.Background(), time.Minute))
+	first := make(chan error)
+	go func() {
+		var names []string
+		first <- db.Query(canceled, query, &names)
+	}()
+	<-raw.started
+
+	second := make(chan error)
+	var names []string
+	go func() {
+		second <- db.Query(WithCacheTTL(context.Background(), time.Minute), query, &names)
+	}()
+	cancel()
+	if err := <-first; !errors.Is(err, context.Canceled) {
+		t.Fatalf("got %v", err)
+	}
+	close(raw.release)
+	if err := <-second; err != nil || len(names) != 1 {
+		t.Fatalf("got %v %v", names, err)
+	}
+	if n := atomic.LoadInt32(&raw.queries); n != 1 {
+		t.Fatalf("got %d queries", n)
+	}
+}
+
+func TestCacheKey(t *testing.T) {
+	a, b := 1, 1
+	at := time.Unix(1600000000, 0).UTC()
+	bt := at
+	var nilInt *int
+	key := func(values ...interface{}) string {
+		return cacheKey(NewTemplate("select * from user where id = ?", values...))
+	}
+	if key(&a) != key(&b) || key(&a) != key(1) {
+		t.Fatal("got different keys for pointers to equal values")
+	}
+	if key(&at) != key(&bt) || key(&at) != key(at) {
+		t.Fatal("got different keys for pointers to equal times")
+	}
+	if key(nilInt) != key(nil) {
+		t.Fatal("got different keys for a nil pointer and nil")
+	}
+	if key(sql.NullInt64{Int64: 1, Valid: true}) != key(int64(1)) {
+		t.Fatal("got different keys for a valuer and its value")
+	}
+	before := key(&a)
+	a = 2
+	if key(&a) == before {
+		t.Fatal("got the same key after the pointed value changed")
+	}
+	if key("1") == key(1) {
+		t.Fatal("got the same key for values of different types")
+	}
+}
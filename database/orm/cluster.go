@@ -0,0 +1,271 @@
+package orm
+
+import (
+	"context"
+	"database/sql"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	BalanceRoundRobin   = "round_robin"
+	BalanceLeastLatency = "least_latency"
+)
+
+type ClusterOptions struct {
+	// Balance is BalanceRoundRobin or BalanceLeastLatency, defaults to BalanceRoundRobin.
+	Balance string
+	// HealthCheckInterval enables background health checks of replicas when positive.
+	HealthCheckInterval time.Duration
+	// HealthCheck defaults to querying "select 1".
+	HealthCheck func(ctx context.Context, db DBTX) error
+}
+
+type Cluster struct {
+	primary  DBTX
+	replicas []*replica
+	options  ClusterOptions
+	next     uint64
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+type replica struct {
+	db      DBTX
+	down    int32
+	latency int64
+}
+
+type primaryKey struct{}
+
+// WithPrimary returns a context that makes a Cluster read from the primary, e.g. right after a write.
+func WithPrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryKey{}, true)
+}
+
+func IsPrimary(ctx context.Context) bool {
+	b, _ := ctx.Value(primaryKey{}).(bool)
+	return b
+}
+
+// NewCluster routes queries to replicas and execs and transactions to primary.
+func NewCluster(primary DBTX, replicas ...DBTX) (*Cluster, error) {
+	return NewClusterWithOptions(ClusterOptions{}, primary, replicas...)
+}
+
+func NewClusterWithOptions(options ClusterOptions, primary DBTX, replicas ...DBTX) (*Cluster, error) {
+	if primary == nil {
+		return nil, errorf("cluster: require primary")
+	}
+	if options.Balance == "" {
+		options.Balance = BalanceRoundRobin
+	}
+	if options.Balance != BalanceRoundRobin && options.Balance != BalanceLeastLatency {
+		return nil, errorf("cluster: invalid balance %q", options.Balance)
+	}
+	if options.HealthCheck == nil {
+		options.HealthCheck = func(ctx context.Context, db DBTX) error {
+			var i int
+			return db.Query(ctx, NewTemplate("select 1"), &i)
+		}
+	}
+	c := &Cluster{primary: primary, options: options, stop: make(chan struct{})}
+	dialectType := reflect.TypeOf(DialectOf(primary))
+	for i, r := range replicas {
+		if t := reflect.TypeOf(DialectOf(r)); t != dialectType {
+			return nil, errorf("cluster: replica %d dialect %s mismatch primary dialect %s", i, t, dialectType)
+		}
+		c.replicas = append(c.replicas, &replica{db: r})
+	}
+	if options.HealthCheckInterval > 0 && len(c.replicas) > 0 {
+		go c.healthCheckLoop()
+	}
+	return c, nil
+}
+
+func (c *Cluster) Primary() DBTX {
+	return c.primary
+}
+
+func (c *Cluster) Unwrap() DBTX {
+	return c.primary
+}
+
+func (c *Cluster) Replicas() []DBTX {
+	result := make([]DBTX, 0, len(c.replicas))
+	for _, r := range c.replicas {
+		result = append(result, r.db)
+	}
+	return result
+}
+
+// HealthyReplicas returns the replicas that passed the last health check.
+func (c *Cluster) HealthyReplicas() []DBTX {
+	result := make([]DBTX, 0, len(c.replicas))
+	for _, r := range c.replicas {
+		if atomic.LoadInt32(&r.down) == 0 {
+			result = append(result, r.db)
+		}
+	}
+	return result
+}
+
+// CheckHealth checks every replica once, ejects the failing ones and restores the recovered ones.
+func (c *Cluster) CheckHealth(ctx context.Context) {
+	var wg sync.WaitGroup
+	for i, r := range c.replicas {
+		wg.Add(1)
+		go func(i int, r *replica) {
+			defer wg.Done()
+			start := time.Now()
+			err := c.options.HealthCheck(ctx, r.db)
+			if err != nil {
+				if atomic.SwapInt32(&r.down, 1) == 0 {
+					debugf("cluster: eject replica %d: %v", i, err)
+				}
+				return
+			}
+			r.observe(time.Since(start))
+			if atomic.SwapInt32(&r.down, 0) == 1 {
+				debugf("cluster: restore replica %d", i)
+			}
+		}(i, r)
+	}
+	wg.Wait()
+}
+
+func (c *Cluster) healthCheckLoop() {
+	ticker := time.NewTicker(c.options.HealthCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-c.stop:
+			return
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), c.options.HealthCheckInterval)
+			c.CheckHealth(ctx)
+			cancel()
+		}
+	}
+}
+
+// Close stops the background health checks, it doesn't close the members.
+func (c *Cluster) Close() error {
+	c.stopOnce.Do(func() {
+		close(c.stop)
+	})
+	return nil
+}
+
+func (r *replica) observe(d time.Duration) {
+	for {
+		old := atomic.LoadInt64(&r.latency)
+		latency := int64(d)
+		if old > 0 {
+			latency = (old*4 + latency) / 5
+		}
+		if latency <= 0 {
+			// zero is the latency of a replica never observed
+			latency = 1
+		}
+		if atomic.CompareAndSwapInt64(&r.latency, old, latency) {
+			return
+		}
+	}
+}
+
+func (c *Cluster) reader(ctx context.Context) (DBTX, *replica) {
+	if IsPrimary(ctx) || len(c.replicas) == 0 {
+		return c.primary, nil
+	}
+	healthy := make([]*replica, 0, len(c.replicas))
+	for _, r := range c.replicas {
+		if atomic.LoadInt32(&r.down) == 0 {
+			healthy = append(healthy, r)
+		}
+	}
+	if len(healthy) == 0 {
+		return c.primary, nil
+	}
+	var picked *replica
+	switch c.options.Balance {
+	case BalanceLeastLatency:
+		// replicas without latency samples yet take turns, so a new one doesn't take all queries until sampled
+		var unknown []*replica
+		for _, r := range healthy {
+			latency := atomic.LoadInt64(&r.latency)
+			if latency == 0 {
+				unknown = append(unknown, r)
+				continue
+			}
+			if picked == nil || latency < atomic.LoadInt64(&picked.latency) {
+				picked = r
+			}
+		}
+		if len(unknown) > 0 {
+			picked = c.roundRobin(unknown)
+		}
+	default:
+		picked = c.roundRobin(healthy)
+	}
+	return picked.db, picked
+}
+
+func (c *Cluster) roundRobin(replicas []*replica) *replica {
+	n := atomic.AddUint64(&c.next, 1) - 1
+	return replicas[n%uint64(len(replicas))]
+}
+
+func (c *Cluster) Dialect() Dialect {
+	return DialectOf(c.primary)
+}
+
+func (c *Cluster) Query(ctx context.Context, t Template, i interface{}) error {
+	db, r := c.reader(ctx)
+	start := time.Now()
+	err := db.Query(ctx, t, i)
+	if r != nil && err == nil {
+		r.observe(time.Since(start))
+	}
+	return err
+}
+
+func (c *Cluster) QueryRows(ctx context.Context, t Template) (*Rows, error) {
+	db, r := c.reader(ctx)
+	start := time.Now()
+	rows, err := QueryRows(ctx, db, t)
+	if r != nil && err == nil {
+		r.observe(time.Since(start))
+	}
+	return rows, err
+}
+
+func (c *Cluster) Exec(ctx context.Context, t Template) (sql.Result, error) {
+	return c.primary.Exec(ctx, t)
+}
+
+func (c *Cluster) Tx(ctx context.Context, fn func(ctx context.Context, tx DBTX) error, opts ...*sql.TxOptions) error {
+	return c.primary.Tx(ctx, fn, opts...)
+}
+
+func (c *Cluster) BeginTx(ctx context.Context, opts ...*sql.TxOptions) (DBTX, error) {
+	return c.primary.BeginTx(ctx, opts...)
+}
+
+func (c *Cluster) Rollback() error {
+	return c.primary.Rollback()
+}
+
+func (c *Cluster) Commit() error {
+	return c.primary.Commit()
+}
+
+func (c *Cluster) Prepare(ctx context.Context, format string) (*sql.Stmt, error) {
+	p, ok := c.primary.(Preparer)
+	if !ok {
+		return nil, errorf("prepare: unsupported db type")
+	}
+	return p.Prepare(ctx, format)
+}
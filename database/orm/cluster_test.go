@@ -0,0 +1,98 @@
+package orm
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+type clusterTestDB struct {
+	DBTX
+	name string
+	down bool
+}
+
+func (db *clusterTestDB) Dialect() Dialect {
+	return &TestDialect{}
+}
+
+func (db *clusterTestDB) Query(ctx context.Context, t Template, i interface{}) error {
+	if db.down {
+		return errors.New("connection refused")
+	}
+	fmt.Printf("%s query: %s\n", db.name, t.Format)
+	return nil
+}
+
+func (db *clusterTestDB) Exec(ctx context.Context, t Template) (sql.Result, error) {
+	fmt.Printf("%s exec: %s\n", db.name, t.Format)
+	return nil, nil
+}
+
+func ExampleCluster() {
+	ctx := context.Background()
+	replica := &clusterTestDB{name: "replica2"}
+	c, err := NewCluster(&clusterTestDB{name: "primary"}, &clusterTestDB{name: "replica1"}, replica)
+	if err != nil {
+		panic(err)
+	}
+	defer c.Close()
+
+	c.Query(ctx, NewTemplate("select 1"), nil)
+	c.Query(ctx, NewTemplate("select 2"), nil)
+	c.Exec(ctx, NewTemplate("delete from user"))
+	c.Query(WithPrimary(ctx), NewTemplate("select 3"), nil)
+
+	replica.down = true
+	c.CheckHealth(ctx)
+	fmt.Println(len(c.HealthyReplicas()))
+	c.Query(ctx, NewTemplate("select 4"), nil)
+	c.Query(ctx, NewTemplate("select 5"), nil)
+	// output:
+	// replica1 query: select 1
+	// replica2 query: select 2
+	// primary exec: delete from user
+	// primary query: select 3
+	// replica1 query: select 1
+	// 1
+	// replica1 query: select 4
+	// replica1 query: select 5
+}
+
+func TestCluster_LeastLatency(t *testing.T) {
+	r1, r2, r3 := &clusterTestDB{name: "r1"}, &clusterTestDB{name: "r2"}, &clusterTestDB{name: "r3"}
+	c, err := NewClusterWithOptions(ClusterOptions{Balance: BalanceLeastLatency}, &clusterTestDB{name: "primary"}, r1, r2, r3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	pick := func(n int) string {
+		var names []string
+		for i := 0; i < n; i++ {
+			db, _ := c.reader(context.Background())
+			names = append(names, db.(*clusterTestDB).name)
+		}
+		return strings.Join(names, " ")
+	}
+
+	if got, want := pick(4), "r1 r2 r3 r1"; got != want {
+		t.Fatalf("got %s for unobserved replicas, want %s", got, want)
+	}
+	c.replicas[0].observe(10 * time.Millisecond)
+	c.replicas[1].observe(5 * time.Millisecond)
+	if got, want := pick(2), "r3 r3"; got != want {
+		t.Fatalf("got %s with an unobserved replica, want %s", got, want)
+	}
+	c.replicas[2].observe(0)
+	if latency := c.replicas[2].latency; latency != 1 {
+		t.Fatalf("got latency %d for a zero sample, want 1", latency)
+	}
+	c.replicas[2].observe(50 * time.Millisecond)
+	if got, want := pick(2), "r2 r2"; got != want {
+		t.Fatalf("got %s for observed replicas, want %s", got, want)
+	}
+}
@@ -22,12 +22,17 @@ func (c *Condition) Append(others ...Template) *Condition {
 }
 
 func (c *Condition) Appendf(format string, values ...interface{}) *Condition {
-	return c.Append(NewTemplate(format, values...))
+	t, err := expandTemplate(format, values)
+	if err != nil {
+		c.err = err
+		return c
+	}
+	return c.Append(t)
 }
 
 func (c *Condition) AppendMap(m map[string]interface{}) *Condition {
-	for k, v := range m {
-		c.Appendf(fmt.Sprintf("%s = ?", k), v)
+	for _, k := range sortedKeys(m) {
+		c.Appendf(fmt.Sprintf("%s = ?", k), m[k])
 	}
 	return c
 }
@@ -57,6 +62,18 @@ func (c *Condition) AppendIn(column string, values ...interface{}) *Condition {
 	return c.Appendf(format, values...)
 }
 
+func (c *Condition) AppendExpr(exprs ...Expr) *Condition {
+	t := And(exprs...).Build(c.dialect)
+	if t.Err != nil {
+		c.err = t.Err
+		return c
+	}
+	if !t.IsEmpty() {
+		c.Append(t.Template.Bracket())
+	}
+	return c
+}
+
 func (c *Condition) Join(sep string, right, left string) Template {
 	if len(c.templates) == 0 {
 		return Template{}
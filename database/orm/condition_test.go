@@ -15,5 +15,5 @@ func ExampleCondition() {
 		Build()
 	fmt.Println(t)
 	// Output: 
-	// "select 'id','name','age','pet' from 'user' where (name = ? and age = ? and male = ? and 'pet' in (?, ?, ?)) and foo = ?": []interface {}{"Medivh", 20, true, "cat", "dog", "tiger", "bar", "Medivh", 20, true, "cat", "dog", "tiger", "bar"}
+	// "select 'id','name','age','pet' from 'user' where (name = ? and age = ? and male = ? and 'pet' in (?, ?, ?)) and foo = ?": []interface {}{"Medivh", 20, true, "cat", "dog", "tiger", "bar"}
 }
@@ -0,0 +1,93 @@
+package orm
+
+import (
+	"fmt"
+	"strings"
+)
+
+type Conflict struct {
+	Columns []string
+	Updates []string
+	Nothing bool
+}
+
+func OnConflict(columns ...string) Conflict {
+	return Conflict{Columns: columns}
+}
+
+func (c Conflict) DoUpdate(columns ...string) Conflict {
+	c.Updates = columns
+	c.Nothing = false
+	return c
+}
+
+func (c Conflict) DoNothing() Conflict {
+	c.Updates = nil
+	c.Nothing = true
+	return c
+}
+
+// UpdateColumns returns the columns to update on conflict,
+// defaults to all insert columns except the conflict columns.
+func (c Conflict) UpdateColumns(insertColumns []string) []string {
+	if len(c.Updates) > 0 {
+		return c.Updates
+	}
+	result := make([]string, 0, len(insertColumns))
+	for _, column := range insertColumns {
+		if containStrings(c.Columns, column) {
+			continue
+		}
+		result = append(result, column)
+	}
+	return result
+}
+
+type ConflictDialect interface {
+	Dialect
+	OnConflict(c Conflict, insertColumns []string) string
+}
+
+func renderConflict(dialect Dialect, c Conflict, insertColumns []string) string {
+	if d, ok := dialect.(ConflictDialect); ok {
+		return d.OnConflict(c, insertColumns)
+	}
+	b := strings.Builder{}
+	b.WriteString(" on conflict")
+	if len(c.Columns) > 0 {
+		b.WriteString(fmt.Sprintf(" (%s)", strings.Join(c.Columns, ", ")))
+	}
+	updates := c.UpdateColumns(insertColumns)
+	if c.Nothing || len(updates) == 0 {
+		b.WriteString(" do nothing")
+		return b.String()
+	}
+	pairs := make([]string, 0, len(updates))
+	for _, column := range updates {
+		pairs = append(pairs, fmt.Sprintf("%s = excluded.%s", column, column))
+	}
+	b.WriteString(" do update set ")
+	b.WriteString(strings.Join(pairs, ", "))
+	return b.String()
+}
+
+type BuilderConflict struct {
+	builder  *Builder
+	conflict Conflict
+}
+
+func (b *Builder) OnConflict(columns ...string) *BuilderConflict {
+	return &BuilderConflict{builder: b, conflict: OnConflict(columns...)}
+}
+
+func (bc *BuilderConflict) DoUpdate(columns ...string) *Builder {
+	c := bc.conflict.DoUpdate(columns...)
+	bc.builder.conflict = &c
+	return bc.builder
+}
+
+func (bc *BuilderConflict) DoNothing() *Builder {
+	c := bc.conflict.DoNothing()
+	bc.builder.conflict = &c
+	return bc.builder
+}
@@ -0,0 +1,21 @@
+package orm
+
+import "fmt"
+
+func ExampleOnConflict() {
+	type User struct {
+		ID   int    `orm:"id"`
+		Name string `orm:"name"`
+	}
+	users := []User{{1, "Medivh"}, {2, "Jason"}}
+	fmt.Println(BulkInsert(&TestDialect{}, users, OnConflict("id").DoUpdate()))
+	fmt.Println(BulkInsert(&TestDialect{}, users, OnConflict("id").DoNothing()))
+	fmt.Println(New(&TestDialect{}).
+		Insert("user", map[string]interface{}{"id": 1, "name": "Medivh"}).
+		OnConflict("id").DoUpdate("name").
+		Build())
+	// output:
+	// "insert into 'User'(id, name) values (?,?), (?,?) on conflict (id) do update set name = excluded.name": []interface {}{1, "Medivh", 2, "Jason"}
+	// "insert into 'User'(id, name) values (?,?), (?,?) on conflict (id) do nothing": []interface {}{1, "Medivh", 2, "Jason"}
+	// "insert into 'user'(id, name) values(?,?) on conflict (id) do update set name = excluded.name": []interface {}{1, "Medivh"}
+}
@@ -0,0 +1,128 @@
+package orm
+
+import (
+	"context"
+	"reflect"
+)
+
+// Cursor iterates rows one by one, binding each row into the same reused value.
+type Cursor[T any] struct {
+	ctx    context.Context
+	rows   *Rows
+	value  T
+	values []interface{}
+	err    error
+}
+
+func NewCursor[T any](ctx context.Context, db DBTX, t Template) (*Cursor[T], error) {
+	rows, err := QueryRows(ctx, db, t)
+	if err != nil {
+		return nil, err
+	}
+	c := &Cursor[T]{ctx: ctx, rows: rows}
+	if err := c.prepare(); err != nil {
+		rows.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+func (c *Cursor[T]) prepare() error {
+	columns, err := c.rows.raw.Columns()
+	if err != nil {
+		return err
+	}
+	value := reflect.ValueOf(&c.value)
+	elem := unrefValueAndInit(value)
+	if _, isType := LookupType(elem.Type()); !isType && isStructValue(elem) {
+		c.values, err = c.rows.structValues(columns, value.Interface())
+		return err
+	}
+	if len(columns) != 1 {
+		return errorf("cursor: require struct type for %d columns", len(columns))
+	}
+	c.values = []interface{}{scanTarget(value.Interface())}
+	return nil
+}
+
+func (c *Cursor[T]) Next() bool {
+	if c.err != nil {
+		return false
+	}
+	if err := c.ctx.Err(); err != nil {
+		c.err = err
+		c.rows.Close()
+		return false
+	}
+	if !c.rows.raw.Next() {
+		c.err = c.rows.raw.Err()
+		c.rows.Close()
+		return false
+	}
+	if err := c.rows.raw.Scan(c.values...); err != nil {
+		c.err = err
+		c.rows.Close()
+		return false
+	}
+	return true
+}
+
+func (c *Cursor[T]) Value() T {
+	return c.value
+}
+
+func (c *Cursor[T]) Err() error {
+	return c.err
+}
+
+func (c *Cursor[T]) Close() error {
+	return c.rows.Close()
+}
+
+func Iterate[T any](ctx context.Context, db DBTX, t Template, fn func(T) error) error {
+	c, err := NewCursor[T](ctx, db, t)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+	for c.Next() {
+		if err := fn(c.Value()); err != nil {
+			return err
+		}
+	}
+	return c.Err()
+}
+
+// IterateKeyset walks the rows of b in batches of size ordered by column, which may end with " desc" as in Keyset,
+// each batch starts after the column value of the previous batch's last row.
+func IterateKeyset[T any](ctx context.Context, db DBTX, b *Builder, column string, size int, fn func([]T) error) error {
+	if size <= 0 {
+		return errorf("iterate keyset: invalid batch size %d", size)
+	}
+	name, _ := parseKeysetColumn(column)
+	var after interface{}
+	for {
+		var batch []T
+		if err := b.Clone().Keyset(column, after, size).Query(ctx, db, &batch); err != nil {
+			return err
+		}
+		if len(batch) == 0 {
+			return nil
+		}
+		if err := fn(batch); err != nil {
+			return err
+		}
+		if len(batch) < size {
+			return nil
+		}
+		last := unrefValue(reflect.ValueOf(&batch[len(batch)-1]))
+		if last.Kind() != reflect.Struct {
+			return ErrRequireStructType
+		}
+		field, err := fieldByColumn(b.dialect, last, name)
+		if err != nil {
+			return err
+		}
+		after = unrefValue(field).Interface()
+	}
+}
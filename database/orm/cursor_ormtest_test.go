@@ -0,0 +1,227 @@
+package orm_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/medivhyang/golib/database/orm"
+	"github.com/medivhyang/golib/database/orm/ormtest"
+)
+
+type cursorUser struct {
+	ID   int    `orm:"id"`
+	Name string `orm:"name"`
+}
+
+func userRows(ids ...int) *ormtest.Rows {
+	rows := ormtest.NewRows("id", "name")
+	for _, id := range ids {
+		rows.AddRow(id, string(rune('a'+id-1)))
+	}
+	return rows
+}
+
+func TestIterate(t *testing.T) {
+	ctx := context.Background()
+	db := ormtest.New(ormtest.Dialect{})
+	db.ExpectQuery("select id, name from user").WillReturnRows(userRows(1, 2, 3))
+	db.ExpectQuery("select id, name from user").WillReturnRows(userRows(1, 2, 3))
+	db.ExpectQuery("select id from user").WillReturnRows(ormtest.NewRows("id").AddRow(1).AddRow(2))
+
+	var got []cursorUser
+	if err := orm.Iterate(ctx, db, orm.NewTemplate("select id, name from user"), func(u cursorUser) error {
+		got = append(got, u)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if want := []cursorUser{{1, "a"}, {2, "b"}, {3, "c"}}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+
+	stop := errors.New("stop")
+	got = nil
+	err := orm.Iterate(ctx, db, orm.NewTemplate("select id, name from user"), func(u cursorUser) error {
+		got = append(got, u)
+		if u.ID == 2 {
+			return stop
+		}
+		return nil
+	})
+	if err != stop {
+		t.Fatalf("got error %v, want %v", err, stop)
+	}
+	if want := []cursorUser{{1, "a"}, {2, "b"}}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+
+	c, err := orm.NewCursor[int](ctx, db, orm.NewTemplate("select id from user"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []int
+	for c.Next() {
+		ids = append(ids, c.Value())
+	}
+	if err := c.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if want := []int{1, 2}; !reflect.DeepEqual(ids, want) {
+		t.Fatalf("got %v, want %v", ids, want)
+	}
+	if c.Next() {
+		t.Fatal("got next after the last row")
+	}
+	if err := db.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestIterateKeyset(t *testing.T) {
+	const (
+		first = `select "id","name" from "user" where name <> ? order by %s limit ?`
+		next  = `select "id","name" from "user" where name <> ? and "id" %s ? order by %s limit ?`
+	)
+	stop := errors.New("stop")
+	tests := []struct {
+		name    string
+		column  string
+		expect  func(db *ormtest.DB)
+		stopAt  int
+		err     error
+		batches [][]int
+	}{
+		{
+			name:   "short last batch",
+			column: "id",
+			expect: func(db *ormtest.DB) {
+				db.ExpectQuery(fmt.Sprintf(first, `"id"`)).WithArgs("", 2).WillReturnRows(userRows(1, 2))
+				db.ExpectQuery(fmt.Sprintf(next, ">", `"id"`)).WithArgs("", 2, 2).WillReturnRows(userRows(3, 4))
+				db.ExpectQuery(fmt.Sprintf(next, ">", `"id"`)).WithArgs("", 4, 2).WillReturnRows(userRows(5))
+			},
+			batches: [][]int{{1, 2}, {3, 4}, {5}},
+		},
+		{
+			name:   "full last batch",
+			column: "id",
+			expect: func(db *ormtest.DB) {
+				db.ExpectQuery(fmt.Sprintf(first, `"id"`)).WithArgs("", 2).WillReturnRows(userRows(1, 2))
+				db.ExpectQuery(fmt.Sprintf(next, ">", `"id"`)).WithArgs("", 2, 2).WillReturnRows(userRows())
+			},
+			batches: [][]int{{1, 2}},
+		},
+		{
+			name:   "descending",
+			column: "id desc",
+			expect: func(db *ormtest.DB) {
+				db.ExpectQuery(fmt.Sprintf(first, `"id" desc`)).WithArgs("", 2).WillReturnRows(userRows(5, 4))
+				db.ExpectQuery(fmt.Sprintf(next, "<", `"id" desc`)).WithArgs("", 4, 2).WillReturnRows(userRows(3))
+			},
+			batches: [][]int{{5, 4}, {3}},
+		},
+		{
+			name:   "early stop",
+			column: "id",
+			expect: func(db *ormtest.DB) {
+				db.ExpectQuery(fmt.Sprintf(first, `"id"`)).WithArgs("", 2).WillReturnRows(userRows(1, 2))
+			},
+			stopAt:  1,
+			err:     stop,
+			batches: [][]int{{1, 2}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := ormtest.New(ormtest.Dialect{})
+			tt.expect(db)
+			b := orm.New(db.Dialect()).Select("user", "id", "name").Where("name <> ?", "")
+			var batches [][]int
+			err := orm.IterateKeyset(context.Background(), db, b, tt.column, 2, func(users []cursorUser) error {
+				var ids []int
+				for _, u := range users {
+					ids = append(ids, u.ID)
+				}
+				batches = append(batches, ids)
+				if len(batches) == tt.stopAt {
+					return stop
+				}
+				return nil
+			})
+			if err != tt.err {
+				t.Fatalf("got error %v, want %v", err, tt.err)
+			}
+			if !reflect.DeepEqual(batches, tt.batches) {
+				t.Fatalf("got batches %v, want %v", batches, tt.batches)
+			}
+			if err := db.ExpectationsWereMet(); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+type cursorPoint struct {
+	X, Y int
+}
+
+func init() {
+	orm.RegisterTypeFunc(func(p cursorPoint) (string, error) {
+		return fmt.Sprintf("%d,%d", p.X, p.Y), nil
+	}, func(s string) (cursorPoint, error) {
+		var p cursorPoint
+		_, err := fmt.Sscanf(s, "%d,%d", &p.X, &p.Y)
+		return p, err
+	})
+}
+
+func TestCursor_RegisteredType(t *testing.T) {
+	ctx := context.Background()
+	db := ormtest.New(ormtest.Dialect{})
+	rows := func() *ormtest.Rows {
+		return ormtest.NewRows("point").AddRow("1,2").AddRow([]byte("3,4"))
+	}
+	db.ExpectQuery("select point from shape").WillReturnRows(rows())
+	db.ExpectQuery("select point from shape").WillReturnRows(rows())
+	db.ExpectQuery("select point from shape").WillReturnRows(rows())
+
+	var bound []cursorPoint
+	if err := db.Query(ctx, orm.NewTemplate("select point from shape"), &bound); err != nil {
+		t.Fatal(err)
+	}
+	c, err := orm.NewCursor[cursorPoint](ctx, db, orm.NewTemplate("select point from shape"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []cursorPoint
+	for c.Next() {
+		got = append(got, c.Value())
+	}
+	if err := c.Err(); err != nil {
+		t.Fatal(err)
+	}
+	want := []cursorPoint{{1, 2}, {3, 4}}
+	if !reflect.DeepEqual(bound, want) || !reflect.DeepEqual(got, want) {
+		t.Fatalf("got cursor %v and bind %v, want %v", got, bound, want)
+	}
+
+	pc, err := orm.NewCursor[*cursorPoint](ctx, db, orm.NewTemplate("select point from shape"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ptrs []cursorPoint
+	for pc.Next() {
+		ptrs = append(ptrs, *pc.Value())
+	}
+	if err := pc.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(ptrs, want) {
+		t.Fatalf("got pointer cursor %v, want %v", ptrs, want)
+	}
+	if err := db.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,16 @@
+package orm
+
+import "fmt"
+
+func ExampleBuilder_Keyset() {
+	t := New(&TestDialect{}).
+		Select("user", "id", "name").
+		Where("age > ?", 18).
+		Keyset("id", 100, 20).
+		Build()
+	fmt.Println(t)
+	fmt.Println(New(&TestDialect{}).Select("user", "id").Keyset("id desc", 100, 20).Build())
+	// output:
+	// "select 'id','name' from 'user' where age > ? and 'id' > ? order by 'id' limit ?": []interface {}{18, 100, 20}
+	// "select 'id' from 'user' where 'id' < ? order by 'id' desc limit ?": []interface {}{100, 20}
+}
@@ -3,6 +3,7 @@ package orm
 import (
 	"context"
 	"database/sql"
+	"fmt"
 )
 
 type DB interface {
@@ -10,106 +11,221 @@ type DB interface {
 	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
 }
 
+type Preparer interface {
+	Prepare(ctx context.Context, format string) (*sql.Stmt, error)
+}
+
+type RowsQuerier interface {
+	QueryRows(ctx context.Context, t Template) (*Rows, error)
+}
+
+// QueryRows queries the rows of t from db, which must implement RowsQuerier as the dbs of this package do.
+func QueryRows(ctx context.Context, db DBTX, t Template) (*Rows, error) {
+	q, ok := db.(RowsQuerier)
+	if !ok {
+		return nil, errorf("query rows: unsupported db type")
+	}
+	return q.QueryRows(ctx, t)
+}
+
 type DBTX interface {
 	Query(ctx context.Context, t Template, i interface{}) error
 	Exec(ctx context.Context, t Template) (sql.Result, error)
-	Tx(ctx context.Context, fn func(ctx context.Context, tx DBTX) error) (err error)
-	BeginTx(ctx context.Context) (DBTX, error)
+	// In a transaction, Tx and BeginTx start a savepoint which takes no options, so non-nil opts return ErrNestedTxOptions.
+	Tx(ctx context.Context, fn func(ctx context.Context, tx DBTX) error, opts ...*sql.TxOptions) (err error)
+	BeginTx(ctx context.Context, opts ...*sql.TxOptions) (DBTX, error)
 	Rollback() error
 	Commit() error
 }
 
+// DialectOf returns the dialect of db if it implements Dialect() Dialect, or the default dialect.
+func DialectOf(db DBTX) Dialect {
+	if d, ok := db.(interface{ Dialect() Dialect }); ok {
+		return d.Dialect()
+	}
+	return GetDefaultDialect()
+}
+
 type db struct {
-	dialect Dialect
-	raw     DB
+	dialect   Dialect
+	raw       DB
+	savepoint string
+	depth     int
+	stmts     *stmtCache
+	// ctx is the context of BeginTx, which releases or rolls back the savepoint.
+	ctx context.Context
 }
 
 func NewDB(dialect Dialect, raw DB) DBTX {
 	return &db{dialect: dialect, raw: raw}
 }
 
-func OpenDB(driverName string, dataSourceName string) (DBTX, error) {
+func OpenDB(driverName string, dataSourceName string, options ...PoolOptions) (DBTX, error) {
+	return OpenDBContext(context.Background(), driverName, dataSourceName, options...)
+}
+
+// OpenDBContext is like OpenDB, ctx bounds the pings and their retries.
+func OpenDBContext(ctx context.Context, driverName string, dataSourceName string, options ...PoolOptions) (DBTX, error) {
+	var opts PoolOptions
+	if len(options) > 0 {
+		opts = options[0]
+	}
 	r, err := sql.Open(driverName, dataSourceName)
 	if err != nil {
 		return nil, err
 	}
-	if err := r.Ping(); err != nil {
+	opts.apply(r)
+	if err := ping(ctx, r, opts); err != nil {
+		r.Close()
 		return nil, err
 	}
 	return NewDB(GetDialect(driverName), r), err
 }
 
+func (db *db) Dialect() Dialect {
+	return db.dialect
+}
+
 func (db *db) Query(ctx context.Context, t Template, i interface{}) error {
-	debugf("query: %s", t.String())
-	rows, err := db.raw.QueryContext(ctx, t.Format, t.Values...)
+	rows, err := db.QueryRows(ctx, t)
 	if err != nil {
 		return err
 	}
-	return NewRows(nil, rows).Bind(i)
+	return rows.Bind(i)
+}
+
+func (db *db) QueryRows(ctx context.Context, t Template) (*Rows, error) {
+	debugf("query: %s", t.String())
+	rows, err := db.queryStmt(ctx, t)
+	if err != nil {
+		return nil, err
+	}
+	return NewRows(db.dialect, rows), nil
 }
 
 func (db *db) Exec(ctx context.Context, t Template) (sql.Result, error) {
 	debugf("exec: %s", t.String())
-	return db.raw.ExecContext(ctx, t.Format, t.Values...)
+	return db.execStmt(ctx, t)
 }
 
-func (db *db) Tx(ctx context.Context, fn func(ctx context.Context, tx DBTX) error) (err error) {
+func (db *db) Prepare(ctx context.Context, format string) (*sql.Stmt, error) {
+	p, ok := db.raw.(interface {
+		PrepareContext(ctx context.Context, query string) (*sql.Stmt, error)
+	})
+	if !ok {
+		return nil, errorf("prepare: unsupported db type")
+	}
+	debugf("prepare: %q", format)
+	return p.PrepareContext(ctx, format)
+}
+
+func (db *db) Tx(ctx context.Context, fn func(ctx context.Context, tx DBTX) error, opts ...*sql.TxOptions) (err error) {
 	var tx DBTX
-	if tx, err = db.BeginTx(ctx); err != nil {
+	if tx, err = db.BeginTx(ctx, opts...); err != nil {
 		return err
 	}
 	defer func() {
 		if x := recover(); x != nil {
 			debugf("tx: catch panic: %v", x)
-			err = tx.Rollback()
+			if rollbackErr := tx.Rollback(); rollbackErr != nil {
+				debugf("tx: rollback failed: %v", rollbackErr)
+			}
+			panic(x)
 		}
 	}()
 	if err := fn(ctx, tx); err != nil {
-		return tx.Rollback()
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			debugf("tx: rollback failed: %v", rollbackErr)
+		}
+		return err
 	}
 	return tx.Commit()
 }
 
-func (db *db) BeginTx(ctx context.Context) (DBTX, error) {
+func (db *db) BeginTx(ctx context.Context, opts ...*sql.TxOptions) (DBTX, error) {
 	tx, ok := db.raw.(*sql.Tx)
 	if ok {
-		return NewDB(db.dialect, tx), nil
+		for _, opt := range opts {
+			if opt != nil {
+				return nil, ErrNestedTxOptions
+			}
+		}
+		savepoint := fmt.Sprintf("sp_%d", db.depth+1)
+		if _, err := tx.ExecContext(ctx, "savepoint "+savepoint); err != nil {
+			debugf("tx: savepoint %s failed: %v", savepoint, err)
+			return nil, err
+		}
+		debugf("tx: savepoint %s success", savepoint)
+		nested := *db
+		nested.savepoint = savepoint
+		nested.depth++
+		nested.ctx = ctx
+		return &nested, nil
 	}
 	r, ok := db.raw.(*sql.DB)
 	if ok {
-		tx, err := r.BeginTx(ctx, nil)
+		var opt *sql.TxOptions
+		if len(opts) > 0 {
+			opt = opts[0]
+		}
+		tx, err := r.BeginTx(ctx, opt)
 		if err != nil {
-			debugf("tx: begin failed: %v", tx)
+			debugf("tx: begin failed: %v", err)
 			return nil, err
 		}
 		debugf("tx: begin tx success")
-		return NewDB(db.dialect, tx), nil
+		return newTxDB(db, tx), nil
 	}
 	return nil, errorf("tx: invalid type")
 }
 
+func newTxDB(parent *db, tx *sql.Tx) *db {
+	return &db{dialect: parent.dialect, raw: tx, stmts: parent.stmts}
+}
+
 func (db *db) Rollback() error {
 	tx, ok := db.raw.(*sql.Tx)
-	if ok {
-		if err := tx.Rollback(); err != nil {
-			debugf("tx: rollback tx failed: %v", err)
+	if !ok {
+		return nil
+	}
+	if db.savepoint != "" {
+		if _, err := tx.ExecContext(db.ctx, "rollback to savepoint "+db.savepoint); err != nil {
+			debugf("tx: rollback to savepoint %s failed: %v", db.savepoint, err)
+			return err
+		}
+		// rollback to keeps the savepoint on the stack, release it as commit does
+		if _, err := tx.ExecContext(db.ctx, "release savepoint "+db.savepoint); err != nil {
+			debugf("tx: release savepoint %s failed: %v", db.savepoint, err)
 			return err
 		}
-		debugf("tx: rollback success")
+		debugf("tx: rollback to savepoint %s success", db.savepoint)
 		return nil
 	}
+	if err := tx.Rollback(); err != nil {
+		debugf("tx: rollback tx failed: %v", err)
+		return err
+	}
+	debugf("tx: rollback success")
 	return nil
 }
 
 func (db *db) Commit() error {
 	tx, ok := db.raw.(*sql.Tx)
-	if ok {
-		if err := tx.Commit(); err != nil {
-			debugf("tx", "commit tx failed: %v", err)
+	if !ok {
+		return nil
+	}
+	if db.savepoint != "" {
+		if _, err := tx.ExecContext(db.ctx, "release savepoint "+db.savepoint); err != nil {
+			debugf("tx: release savepoint %s failed: %v", db.savepoint, err)
 			return err
 		}
-		debugf("tx", "commit tx success")
+		debugf("tx: release savepoint %s success", db.savepoint)
 		return nil
 	}
+	if err := tx.Commit(); err != nil {
+		debugf("tx: commit tx failed: %v", err)
+		return err
+	}
+	debugf("tx: commit tx success")
 	return nil
 }
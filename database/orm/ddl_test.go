@@ -29,3 +29,43 @@ func ExampleDropTablesIfExists() {
 	// output:
 	// "drop table if exists User;"
 }
+
+type testBaseModel struct {
+	ID int `orm:"id integer primary key"`
+}
+
+type testAddress struct {
+	City string `orm:"city"`
+	Zip  string `orm:"zip"`
+}
+
+func ExampleCreateTables() {
+	type User struct {
+		testBaseModel
+		Name    string      `orm:"name"`
+		Address testAddress `orm:",prefix=addr_"`
+		Temp    string      `orm:"-"`
+	}
+
+	t := CreateTables(&TestDialect{}, User{})
+	fmt.Println(t)
+
+	// output:
+	// "create table User ('id' integer primary key, 'name' text, 'addr_city' text, 'addr_zip' text);"
+}
+
+func ExampleCreateTables_flatten() {
+	type Order struct {
+		ID      int          `orm:"id integer primary key"`
+		Address testAddress  `orm:",flatten"`
+		Billing *testAddress `orm:",flatten,prefix=billing_"`
+	}
+
+	fmt.Println(CreateTables(&TestDialect{}, Order{}))
+	pairs, err := ParseColumnValuePairs(&TestDialect{}, Order{ID: 1, Address: testAddress{City: "Paris", Zip: "75001"}})
+	fmt.Println(pairs, err)
+
+	// output:
+	// "create table Order ('id' integer primary key, 'city' text, 'zip' text, 'billing_city' text, 'billing_zip' text);"
+	// [{id 1} {city Paris} {zip 75001} {billing_city <nil>} {billing_zip <nil>}] <nil>
+}
@@ -0,0 +1,181 @@
+package mysql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/medivhyang/golib/database/orm"
+)
+
+func init() {
+	orm.RegisterDialect("mysql", &Dialect{})
+}
+
+type Dialect struct{}
+
+func (d *Dialect) MappingType(rt reflect.Type) string {
+	if rt.Kind() == reflect.Ptr {
+		rt = rt.Elem()
+	}
+	switch rt.Kind() {
+	case reflect.Bool:
+		return "tinyint(1)"
+	case reflect.Int8, reflect.Int16, reflect.Int32,
+		reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return "int"
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint64:
+		return "bigint"
+	case reflect.Float32:
+		return "float"
+	case reflect.Float64:
+		return "double"
+	case reflect.String:
+		return "varchar(255)"
+	case reflect.Slice:
+		if rt.Elem().Kind() == reflect.Uint8 {
+			return "blob"
+		}
+		return ""
+	default:
+		switch rt.String() {
+		case "time.Time":
+			return "datetime"
+		}
+		return ""
+	}
+}
+
+func (d *Dialect) JSONType() string {
+	return "json"
+}
+
+func (d *Dialect) Quote(s string) string {
+	return fmt.Sprintf("`%s`", s)
+}
+
+func (d *Dialect) OnConflict(c orm.Conflict, insertColumns []string) string {
+	updates := c.UpdateColumns(insertColumns)
+	if c.Nothing || len(updates) == 0 {
+		if len(insertColumns) == 0 {
+			return ""
+		}
+		return fmt.Sprintf(" on duplicate key update %s = %s", insertColumns[0], insertColumns[0])
+	}
+	pairs := make([]string, 0, len(updates))
+	for _, column := range updates {
+		pairs = append(pairs, fmt.Sprintf("%s = values(%s)", column, column))
+	}
+	return " on duplicate key update " + strings.Join(pairs, ", ")
+}
+
+func (d *Dialect) MaxParams() int {
+	return 65535
+}
+
+// IsRetryable reports deadlock (1213) and lock wait timeout (1205) errors,
+// read from the Number field of the driver's *mysql.MySQLError.
+func (d *Dialect) IsRetryable(err error) bool {
+	for ; err != nil; err = errors.Unwrap(err) {
+		rv := reflect.ValueOf(err)
+		for rv.Kind() == reflect.Ptr && !rv.IsNil() {
+			rv = rv.Elem()
+		}
+		if rv.Kind() != reflect.Struct {
+			continue
+		}
+		if f := rv.FieldByName("Number"); f.Kind() == reflect.Uint16 {
+			return f.Uint() == 1213 || f.Uint() == 1205
+		}
+	}
+	return false
+}
+
+func (d *Dialect) Inspect(ctx context.Context, db orm.DBTX, tables ...string) ([]orm.TableInfo, error) {
+	if len(tables) == 0 {
+		t := orm.NewTemplate("select table_name as table_name from information_schema.tables where table_schema = database() and table_type = 'BASE TABLE' order by table_name")
+		if err := db.Query(ctx, t, &tables); err != nil {
+			return nil, err
+		}
+	}
+	result := make([]orm.TableInfo, 0, len(tables))
+	for _, table := range tables {
+		info, err := d.inspectTable(ctx, db, table)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, info)
+	}
+	return result, nil
+}
+
+// inspectTable selects information_schema columns with aliases, which keep them in lower case on MySQL 8.
+func (d *Dialect) inspectTable(ctx context.Context, db orm.DBTX, table string) (orm.TableInfo, error) {
+	info := orm.TableInfo{Name: table}
+
+	var columns []struct {
+		Name     string  `orm:"column_name"`
+		Type     string  `orm:"column_type"`
+		Nullable string  `orm:"is_nullable"`
+		Key      string  `orm:"column_key"`
+		Default  *string `orm:"column_default"`
+	}
+	t := orm.NewTemplate("select column_name as column_name, column_type as column_type, is_nullable as is_nullable, column_key as column_key, column_default as column_default from information_schema.columns where table_schema = database() and table_name = ? order by ordinal_position", table)
+	if err := db.Query(ctx, t, &columns); err != nil {
+		return info, err
+	}
+	if len(columns) == 0 {
+		return info, fmt.Errorf("orm: inspect: not found table %s", table)
+	}
+	for _, c := range columns {
+		info.Columns = append(info.Columns, orm.ColumnInfo{
+			Name:       c.Name,
+			Type:       c.Type,
+			Nullable:   c.Nullable == "YES",
+			PrimaryKey: c.Key == "PRI",
+			Default:    c.Default,
+		})
+	}
+
+	var indexes []struct {
+		Name      string `orm:"index_name"`
+		NonUnique int    `orm:"non_unique"`
+		Column    string `orm:"column_name"`
+	}
+	t = orm.NewTemplate("select index_name as index_name, non_unique as non_unique, column_name as column_name from information_schema.statistics where table_schema = database() and table_name = ? and index_name <> 'PRIMARY' order by index_name, seq_in_index", table)
+	if err := db.Query(ctx, t, &indexes); err != nil {
+		return info, err
+	}
+	for _, index := range indexes {
+		n := len(info.Indexes)
+		if n == 0 || info.Indexes[n-1].Name != index.Name {
+			info.Indexes = append(info.Indexes, orm.IndexInfo{Name: index.Name, Unique: index.NonUnique == 0})
+			n++
+		}
+		info.Indexes[n-1].Columns = append(info.Indexes[n-1].Columns, index.Column)
+	}
+
+	var keys []struct {
+		Name      string `orm:"constraint_name"`
+		Column    string `orm:"column_name"`
+		RefTable  string `orm:"referenced_table_name"`
+		RefColumn string `orm:"referenced_column_name"`
+	}
+	t = orm.NewTemplate("select constraint_name as constraint_name, column_name as column_name, referenced_table_name as referenced_table_name, referenced_column_name as referenced_column_name from information_schema.key_column_usage where table_schema = database() and table_name = ? and referenced_table_name is not null order by constraint_name, ordinal_position", table)
+	if err := db.Query(ctx, t, &keys); err != nil {
+		return info, err
+	}
+	var last string
+	for _, k := range keys {
+		if len(info.ForeignKeys) == 0 || last != k.Name {
+			info.ForeignKeys = append(info.ForeignKeys, orm.ForeignKeyInfo{RefTable: k.RefTable})
+			last = k.Name
+		}
+		fk := &info.ForeignKeys[len(info.ForeignKeys)-1]
+		fk.Columns = append(fk.Columns, k.Column)
+		fk.RefColumns = append(fk.RefColumns, k.RefColumn)
+	}
+	return info, nil
+}
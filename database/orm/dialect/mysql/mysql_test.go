@@ -0,0 +1,63 @@
+package mysql
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/medivhyang/golib/database/orm"
+	"github.com/medivhyang/golib/database/orm/ormtest"
+)
+
+func TestInspect(t *testing.T) {
+	db := ormtest.New(&Dialect{})
+	db.ExpectQueryRegexp(`from information_schema\.tables `).
+		WillReturnRows(ormtest.NewRows("table_name").AddRow("user"))
+	db.ExpectQueryRegexp(`from information_schema\.columns `).WithArgs("user").
+		WillReturnRows(ormtest.NewRows("column_name", "column_type", "is_nullable", "column_key", "column_default").
+			AddRow("id", "bigint", "NO", "PRI", nil).
+			AddRow("org_id", "bigint", "YES", "MUL", nil).
+			AddRow("email", "varchar(255)", "NO", "UNI", "").
+			AddRow("nickname", "varchar(64)", "YES", "", nil))
+	db.ExpectQueryRegexp(`from information_schema\.statistics `).WithArgs("user").
+		WillReturnRows(ormtest.NewRows("index_name", "non_unique", "column_name").
+			AddRow("idx_email", 0, "email").
+			AddRow("idx_org_nickname", 1, "org_id").
+			AddRow("idx_org_nickname", 1, "nickname"))
+	db.ExpectQueryRegexp(`from information_schema\.key_column_usage `).WithArgs("user").
+		WillReturnRows(ormtest.NewRows("constraint_name", "column_name", "referenced_table_name", "referenced_column_name").
+			AddRow("fk_org", "org_id", "org", "id"))
+	db.ExpectQueryRegexp(`from information_schema\.columns `).WithArgs("missing").
+		WillReturnRows(ormtest.NewRows("column_name", "column_type", "is_nullable", "column_key", "column_default"))
+
+	infos, err := orm.Inspect(context.Background(), db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	empty := ""
+	want := []orm.TableInfo{
+		{
+			Name: "user",
+			Columns: []orm.ColumnInfo{
+				{Name: "id", Type: "bigint", PrimaryKey: true},
+				{Name: "org_id", Type: "bigint", Nullable: true},
+				{Name: "email", Type: "varchar(255)", Default: &empty},
+				{Name: "nickname", Type: "varchar(64)", Nullable: true},
+			},
+			Indexes: []orm.IndexInfo{
+				{Name: "idx_email", Columns: []string{"email"}, Unique: true},
+				{Name: "idx_org_nickname", Columns: []string{"org_id", "nickname"}},
+			},
+			ForeignKeys: []orm.ForeignKeyInfo{{Columns: []string{"org_id"}, RefTable: "org", RefColumns: []string{"id"}}},
+		},
+	}
+	if !reflect.DeepEqual(infos, want) {
+		t.Fatalf("got %+v, want %+v", infos, want)
+	}
+	if _, err := orm.Inspect(context.Background(), db, "missing"); err == nil {
+		t.Fatal("got nil error for a missing table")
+	}
+	if err := db.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -1,10 +1,12 @@
 package sqlite3
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"reflect"
 
-	_ "github.com/mattn/go-sqlite3"
+	"github.com/mattn/go-sqlite3"
 	"github.com/medivhyang/golib/database/orm"
 )
 
@@ -21,12 +23,17 @@ func (d *Dialect) MappingType(rt reflect.Type) string {
 	switch rt.Kind() {
 	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
 		reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
-		reflect.Int, reflect.Bool:
+		reflect.Int, reflect.Uint, reflect.Bool:
 		return "integer"
 	case reflect.Float32, reflect.Float64:
 		return "real"
 	case reflect.String:
 		return "text"
+	case reflect.Slice:
+		if rt.Elem().Kind() == reflect.Uint8 {
+			return "blob"
+		}
+		return ""
 	default:
 		switch rt.String() {
 		case "time.Time":
@@ -36,6 +43,119 @@ func (d *Dialect) MappingType(rt reflect.Type) string {
 	}
 }
 
+func (d *Dialect) JSONType() string {
+	return "text"
+}
+
 func (d *Dialect) Quote(s string) string {
-	return fmt.Sprintf("'%s'", s)
+	return fmt.Sprintf("\"%s\"", s)
+}
+
+func (d *Dialect) MaxParams() int {
+	return 32766
+}
+
+func (d *Dialect) IsRetryable(err error) bool {
+	var e sqlite3.Error
+	if errors.As(err, &e) {
+		return e.Code == sqlite3.ErrBusy || e.Code == sqlite3.ErrLocked
+	}
+	return false
+}
+
+func (d *Dialect) Inspect(ctx context.Context, db orm.DBTX, tables ...string) ([]orm.TableInfo, error) {
+	if len(tables) == 0 {
+		t := orm.NewTemplate("select name from sqlite_master where type = 'table' and name not like 'sqlite_%' order by name")
+		if err := db.Query(ctx, t, &tables); err != nil {
+			return nil, err
+		}
+	}
+	result := make([]orm.TableInfo, 0, len(tables))
+	for _, table := range tables {
+		info, err := d.inspectTable(ctx, db, table)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, info)
+	}
+	return result, nil
+}
+
+func (d *Dialect) inspectTable(ctx context.Context, db orm.DBTX, table string) (orm.TableInfo, error) {
+	info := orm.TableInfo{Name: table}
+
+	var columns []struct {
+		Name    string  `orm:"name"`
+		Type    string  `orm:"type"`
+		NotNull bool    `orm:"notnull"`
+		Default *string `orm:"dflt_value"`
+		PK      int     `orm:"pk"`
+	}
+	if err := db.Query(ctx, orm.NewTemplate(fmt.Sprintf("pragma table_info(%s)", d.Quote(table))), &columns); err != nil {
+		return info, err
+	}
+	if len(columns) == 0 {
+		return info, fmt.Errorf("orm: inspect: not found table %s", table)
+	}
+	for _, c := range columns {
+		info.Columns = append(info.Columns, orm.ColumnInfo{
+			Name:       c.Name,
+			Type:       c.Type,
+			Nullable:   !c.NotNull && c.PK == 0,
+			PrimaryKey: c.PK > 0,
+			Default:    c.Default,
+		})
+	}
+
+	var indexes []struct {
+		Name   string `orm:"name"`
+		Unique bool   `orm:"unique"`
+		Origin string `orm:"origin"`
+	}
+	if err := db.Query(ctx, orm.NewTemplate(fmt.Sprintf("pragma index_list(%s)", d.Quote(table))), &indexes); err != nil {
+		return info, err
+	}
+	for i := len(indexes) - 1; i >= 0; i-- {
+		index := indexes[i]
+		if index.Origin == "pk" {
+			continue
+		}
+		var names []struct {
+			Name string `orm:"name"`
+		}
+		if err := db.Query(ctx, orm.NewTemplate(fmt.Sprintf("pragma index_info(%s)", d.Quote(index.Name))), &names); err != nil {
+			return info, err
+		}
+		item := orm.IndexInfo{Name: index.Name, Unique: index.Unique}
+		for _, n := range names {
+			item.Columns = append(item.Columns, n.Name)
+		}
+		info.Indexes = append(info.Indexes, item)
+	}
+
+	var keys []struct {
+		ID    int     `orm:"id"`
+		Table string  `orm:"table"`
+		From  string  `orm:"from"`
+		To    *string `orm:"to"`
+	}
+	if err := db.Query(ctx, orm.NewTemplate(fmt.Sprintf("pragma foreign_key_list(%s)", d.Quote(table))), &keys); err != nil {
+		return info, err
+	}
+	byID := map[int]int{}
+	for _, k := range keys {
+		i, ok := byID[k.ID]
+		if !ok {
+			i = len(info.ForeignKeys)
+			byID[k.ID] = i
+			info.ForeignKeys = append(info.ForeignKeys, orm.ForeignKeyInfo{RefTable: k.Table})
+		}
+		to := ""
+		if k.To != nil {
+			to = *k.To
+		}
+		info.ForeignKeys[i].Columns = append(info.ForeignKeys[i].Columns, k.From)
+		info.ForeignKeys[i].RefColumns = append(info.ForeignKeys[i].RefColumns, to)
+	}
+	return info, nil
 }
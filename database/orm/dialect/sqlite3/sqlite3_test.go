@@ -0,0 +1,110 @@
+package sqlite3
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/medivhyang/golib/database/orm"
+)
+
+func TestInspect(t *testing.T) {
+	ctx := context.Background()
+	raw, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "inspect.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer raw.Close()
+	for _, stmt := range []string{
+		`create table org (id integer primary key, name text not null)`,
+		`create table user (
+			id integer primary key,
+			org_id integer references org(id),
+			email varchar(255) not null default '',
+			nickname text
+		)`,
+		`create unique index idx_user_email on user(email)`,
+		`create index idx_user_org_nickname on user(org_id, nickname)`,
+	} {
+		if _, err := raw.Exec(stmt); err != nil {
+			t.Fatal(err)
+		}
+	}
+	db := orm.NewDB(&Dialect{}, raw)
+
+	infos, err := orm.Inspect(ctx, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	empty := "''"
+	want := []orm.TableInfo{
+		{
+			Name: "org",
+			Columns: []orm.ColumnInfo{
+				{Name: "id", Type: "INTEGER", PrimaryKey: true},
+				{Name: "name", Type: "TEXT"},
+			},
+		},
+		{
+			Name: "user",
+			Columns: []orm.ColumnInfo{
+				{Name: "id", Type: "INTEGER", PrimaryKey: true},
+				{Name: "org_id", Type: "INTEGER", Nullable: true},
+				{Name: "email", Type: "varchar(255)", Default: &empty},
+				{Name: "nickname", Type: "TEXT", Nullable: true},
+			},
+			Indexes: []orm.IndexInfo{
+				{Name: "idx_user_email", Columns: []string{"email"}, Unique: true},
+				{Name: "idx_user_org_nickname", Columns: []string{"org_id", "nickname"}},
+			},
+			ForeignKeys: []orm.ForeignKeyInfo{{Columns: []string{"org_id"}, RefTable: "org", RefColumns: []string{"id"}}},
+		},
+	}
+	if !reflect.DeepEqual(infos, want) {
+		t.Fatalf("got %+v, want %+v", infos, want)
+	}
+
+	infos, err = orm.Inspect(ctx, db, "org")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(infos, want[:1]) {
+		t.Fatalf("got %+v, want %+v", infos, want[:1])
+	}
+	if _, err := orm.Inspect(ctx, db, "missing"); err == nil {
+		t.Fatal("got nil error for a missing table")
+	}
+}
+
+func TestQuote(t *testing.T) {
+	d := &Dialect{}
+	if got, want := d.Quote("order"), `"order"`; got != want {
+		t.Fatalf("got %s, want %s", got, want)
+	}
+
+	raw, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer raw.Close()
+	if _, err := raw.Exec(`create table "order" (id integer primary key, "group" text)`); err != nil {
+		t.Fatal(err)
+	}
+	db := orm.NewDB(d, raw)
+	ctx := context.Background()
+	if _, err := orm.New(d).Insert("order", map[string]interface{}{"id": 1, `"group"`: "a"}).Exec(ctx, db); err != nil {
+		t.Fatal(err)
+	}
+	var rows []struct {
+		ID    int    `orm:"id"`
+		Group string `orm:"group"`
+	}
+	if err := orm.New(d).Select("order", "id", "group").Query(ctx, db, &rows); err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 1 || rows[0].ID != 1 || rows[0].Group != "a" {
+		t.Fatalf("got %+v, want the column values, not the quoted names", rows)
+	}
+}
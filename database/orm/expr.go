@@ -0,0 +1,252 @@
+package orm
+
+import (
+	"fmt"
+	"strings"
+)
+
+type Expr struct {
+	op       string
+	children []Expr
+	build    func(dialect Dialect) TemplateWithError
+}
+
+type ColumnRef string
+
+func Col(name string) ColumnRef {
+	return ColumnRef(name)
+}
+
+func Raw(format string, values ...interface{}) Expr {
+	return leafExpr(func(dialect Dialect) TemplateWithError {
+		return TemplateWithError{Template: NewTemplate(format, values...)}
+	})
+}
+
+func And(exprs ...Expr) Expr {
+	return Expr{op: "and", children: exprs}
+}
+
+func Or(exprs ...Expr) Expr {
+	return Expr{op: "or", children: exprs}
+}
+
+func Not(e Expr) Expr {
+	return Expr{op: "not", children: []Expr{e}}
+}
+
+func Exists(subquery *Builder) Expr {
+	return leafExpr(func(dialect Dialect) TemplateWithError {
+		t := subquery.Build()
+		if t.Err != nil {
+			return t
+		}
+		return TemplateWithError{Template: t.Template.Bracket().Wrap("exists ", "")}
+	})
+}
+
+func NotExists(subquery *Builder) Expr {
+	return Not(Exists(subquery))
+}
+
+func leafExpr(build func(dialect Dialect) TemplateWithError) Expr {
+	return Expr{build: build}
+}
+
+func (e Expr) And(others ...Expr) Expr {
+	return And(append([]Expr{e}, others...)...)
+}
+
+func (e Expr) Or(others ...Expr) Expr {
+	return Or(append([]Expr{e}, others...)...)
+}
+
+// IsEmpty reports whether e renders nothing, an and, or or not of empty operands is empty too.
+func (e Expr) IsEmpty() bool {
+	if e.build != nil {
+		return false
+	}
+	for _, child := range e.children {
+		if !child.IsEmpty() {
+			return false
+		}
+	}
+	return true
+}
+
+func (e Expr) Build(dialect Dialect) TemplateWithError {
+	if dialect == nil {
+		dialect = GetDefaultDialect()
+	}
+	switch e.op {
+	case "":
+		if e.build == nil {
+			return TemplateWithError{}
+		}
+		return e.build(dialect)
+	case "not":
+		t := e.children[0].Build(dialect)
+		if t.Err != nil || t.IsEmpty() {
+			return t
+		}
+		return TemplateWithError{Template: t.Template.Bracket().Wrap("not ", "")}
+	default:
+		tt := make(Templates, 0, len(e.children))
+		for _, child := range e.children {
+			if child.IsEmpty() {
+				continue
+			}
+			t := child.buildChild(dialect, e.op)
+			if t.Err != nil {
+				return t
+			}
+			if t.IsEmpty() {
+				continue
+			}
+			tt = append(tt, t.Template)
+		}
+		return TemplateWithError{Template: tt.Join(fmt.Sprintf(" %s ", e.op), "", "")}
+	}
+}
+
+func (e Expr) buildChild(dialect Dialect, parentOp string) TemplateWithError {
+	t := e.Build(dialect)
+	if t.Err != nil || t.IsEmpty() {
+		return t
+	}
+	if (e.op == "and" || e.op == "or") && e.op != parentOp {
+		t.Template = t.Template.Bracket()
+	}
+	return t
+}
+
+func (c ColumnRef) Eq(value interface{}) Expr {
+	if value == nil {
+		return c.IsNull()
+	}
+	return c.compare("=", value)
+}
+
+func (c ColumnRef) Ne(value interface{}) Expr {
+	if value == nil {
+		return c.IsNotNull()
+	}
+	return c.compare("<>", value)
+}
+
+func (c ColumnRef) Gt(value interface{}) Expr {
+	return c.compare(">", value)
+}
+
+func (c ColumnRef) Gte(value interface{}) Expr {
+	return c.compare(">=", value)
+}
+
+func (c ColumnRef) Lt(value interface{}) Expr {
+	return c.compare("<", value)
+}
+
+func (c ColumnRef) Lte(value interface{}) Expr {
+	return c.compare("<=", value)
+}
+
+func (c ColumnRef) Like(pattern interface{}) Expr {
+	return c.compare("like", pattern)
+}
+
+func (c ColumnRef) NotLike(pattern interface{}) Expr {
+	return c.compare("not like", pattern)
+}
+
+func (c ColumnRef) IsNull() Expr {
+	return leafExpr(func(dialect Dialect) TemplateWithError {
+		return TemplateWithError{Template: NewTemplate(fmt.Sprintf("%s is null", quoteColumn(dialect, string(c))))}
+	})
+}
+
+func (c ColumnRef) IsNotNull() Expr {
+	return leafExpr(func(dialect Dialect) TemplateWithError {
+		return TemplateWithError{Template: NewTemplate(fmt.Sprintf("%s is not null", quoteColumn(dialect, string(c))))}
+	})
+}
+
+func (c ColumnRef) Between(from, to interface{}) Expr {
+	return leafExpr(func(dialect Dialect) TemplateWithError {
+		left, err := exprValue(dialect, from)
+		if err != nil {
+			return TemplateWithError{Err: err}
+		}
+		right, err := exprValue(dialect, to)
+		if err != nil {
+			return TemplateWithError{Err: err}
+		}
+		t := NewTemplate(fmt.Sprintf("%s between ", quoteColumn(dialect, string(c)))).
+			Merge(left).
+			Appendf(" and ").
+			Merge(right)
+		return TemplateWithError{Template: t}
+	})
+}
+
+func (c ColumnRef) In(values ...interface{}) Expr {
+	return c.in("in", "1 = 0", values)
+}
+
+func (c ColumnRef) NotIn(values ...interface{}) Expr {
+	return c.in("not in", "1 = 1", values)
+}
+
+func (c ColumnRef) in(op string, empty string, values []interface{}) Expr {
+	return leafExpr(func(dialect Dialect) TemplateWithError {
+		if len(values) == 1 {
+			if sub, ok := values[0].(*Builder); ok {
+				t := sub.Build()
+				if t.Err != nil {
+					return t
+				}
+				return TemplateWithError{Template: NewTemplate(fmt.Sprintf("%s %s ", quoteColumn(dialect, string(c)), op)).Merge(t.Template.Bracket())}
+			}
+		}
+		if len(values) == 0 {
+			return TemplateWithError{Template: NewTemplate(empty)}
+		}
+		holders := repeatString("?", len(values))
+		return TemplateWithError{Template: NewTemplate(fmt.Sprintf("%s %s (%s)", quoteColumn(dialect, string(c)), op, strings.Join(holders, ", ")), values...)}
+	})
+}
+
+func (c ColumnRef) compare(op string, value interface{}) Expr {
+	return leafExpr(func(dialect Dialect) TemplateWithError {
+		v, err := exprValue(dialect, value)
+		if err != nil {
+			return TemplateWithError{Err: err}
+		}
+		return TemplateWithError{Template: NewTemplate(fmt.Sprintf("%s %s ", quoteColumn(dialect, string(c)), op)).Merge(v)}
+	})
+}
+
+func exprValue(dialect Dialect, value interface{}) (Template, error) {
+	switch v := value.(type) {
+	case ColumnRef:
+		return NewTemplate(quoteColumn(dialect, string(v))), nil
+	case Expr:
+		t := v.Build(dialect)
+		return t.Template.Bracket(), t.Err
+	case *Builder:
+		t := v.Build()
+		return t.Template.Bracket(), t.Err
+	default:
+		return NewTemplate("?", value), nil
+	}
+}
+
+func quoteColumn(dialect Dialect, name string) string {
+	parts := strings.Split(name, ".")
+	for i, part := range parts {
+		if part == "*" {
+			continue
+		}
+		parts[i] = dialect.Quote(part)
+	}
+	return strings.Join(parts, ".")
+}
@@ -0,0 +1,34 @@
+package orm
+
+import "fmt"
+
+func ExampleExpr() {
+	dialect := &TestDialect{}
+	e := Col("age").Gt(18).
+		And(Col("name").Like("a%")).
+		Or(Col("deleted_at").IsNull(), Not(Col("role").In("admin", "root")))
+	fmt.Println(e.Build(dialect))
+
+	sub := New(dialect).Select("order", "id").WhereExpr(Col("order.user_id").Eq(Col("user.id")))
+	t := New(dialect).
+		Select("user", "id").
+		WhereExpr(Col("score").Between(60, 100), Col("tag").NotIn(), Exists(sub)).
+		Build()
+	fmt.Println(t)
+	// output:
+	// "('age' > ? and 'name' like ?) or 'deleted_at' is null or not ('role' in (?, ?))": []interface {}{18, "a%", "admin", "root"}
+	// "select 'id' from 'user' where ('score' between ? and ? and 1 = 1 and exists (select 'id' from 'order' where ('order'.'user_id' = 'user'.'id')))": []interface {}{60, 100}
+}
+
+func ExampleExpr_empty() {
+	dialect := &TestDialect{}
+	fmt.Println(Not(And()).Build(dialect))
+	fmt.Println(Col("age").Gt(18).And(Or(And(), Not(Or())), Raw("")).Build(dialect))
+	fmt.Println(Or(Col("age").Gt(18), Not(And(Or()))).Build(dialect))
+	fmt.Println(New(dialect).Select("user", "id").WhereExpr(Not(And()), And(Or())).Build())
+	// output:
+	// ""
+	// "'age' > ?": []interface {}{18}
+	// "'age' > ?": []interface {}{18}
+	// "select 'id' from 'user'"
+}
@@ -0,0 +1,223 @@
+package orm
+
+import (
+	"context"
+	"database/sql"
+	"reflect"
+	"time"
+)
+
+type QueryEvent struct {
+	Action       string
+	Template     Template
+	StartTime    time.Time
+	Duration     time.Duration
+	RowsAffected int64
+	Err          error
+}
+
+type Hook interface {
+	BeforeQuery(ctx context.Context, e *QueryEvent) context.Context
+	AfterQuery(ctx context.Context, e *QueryEvent)
+}
+
+type HookFunc func(ctx context.Context, e *QueryEvent)
+
+func (f HookFunc) BeforeQuery(ctx context.Context, e *QueryEvent) context.Context {
+	return ctx
+}
+
+func (f HookFunc) AfterQuery(ctx context.Context, e *QueryEvent) {
+	f(ctx, e)
+}
+
+func SlowQueryHook(threshold time.Duration, logger Logger) Hook {
+	return HookFunc(func(ctx context.Context, e *QueryEvent) {
+		if e.Duration >= threshold {
+			logger.Printf("%sslow %s: %s, duration: %s", debugPrefix, e.Action, e.Template.String(), e.Duration)
+		}
+	})
+}
+
+type hookDB struct {
+	DBTX
+	hooks []Hook
+}
+
+// WithHooks wraps db so that every query and exec, including those in transactions, runs through hooks.
+func WithHooks(db DBTX, hooks ...Hook) DBTX {
+	if h, ok := db.(*hookDB); ok {
+		return &hookDB{DBTX: h.DBTX, hooks: append(append([]Hook{}, h.hooks...), hooks...)}
+	}
+	return &hookDB{DBTX: db, hooks: hooks}
+}
+
+func (db *hookDB) Dialect() Dialect {
+	return DialectOf(db.DBTX)
+}
+
+func (db *hookDB) Unwrap() DBTX {
+	return db.DBTX
+}
+
+func (db *hookDB) before(ctx context.Context, action string, t Template) (context.Context, *QueryEvent) {
+	e := &QueryEvent{Action: action, Template: t, StartTime: time.Now()}
+	for _, h := range db.hooks {
+		ctx = h.BeforeQuery(ctx, e)
+	}
+	return ctx, e
+}
+
+func (db *hookDB) after(ctx context.Context, e *QueryEvent, err error) {
+	e.Duration = time.Since(e.StartTime)
+	e.Err = err
+	for i := len(db.hooks) - 1; i >= 0; i-- {
+		db.hooks[i].AfterQuery(ctx, e)
+	}
+}
+
+func (db *hookDB) Query(ctx context.Context, t Template, i interface{}) error {
+	ctx, e := db.before(ctx, "query", t)
+	err := db.DBTX.Query(ctx, t, i)
+	db.after(ctx, e, err)
+	return err
+}
+
+// QueryRows runs the after hooks when the rows are closed, so the duration includes the iteration.
+func (db *hookDB) QueryRows(ctx context.Context, t Template) (*Rows, error) {
+	ctx, e := db.before(ctx, "query", t)
+	rows, err := QueryRows(ctx, db.DBTX, t)
+	if err != nil {
+		db.after(ctx, e, err)
+		return nil, err
+	}
+	onClose := rows.onClose
+	rows.onClose = func(err error) {
+		if onClose != nil {
+			onClose(err)
+		}
+		db.after(ctx, e, err)
+	}
+	return rows, nil
+}
+
+func (db *hookDB) Exec(ctx context.Context, t Template) (sql.Result, error) {
+	ctx, e := db.before(ctx, "exec", t)
+	result, err := db.DBTX.Exec(ctx, t)
+	if err == nil {
+		e.RowsAffected, _ = result.RowsAffected()
+	}
+	db.after(ctx, e, err)
+	return result, err
+}
+
+func (db *hookDB) Tx(ctx context.Context, fn func(ctx context.Context, tx DBTX) error, opts ...*sql.TxOptions) error {
+	return db.DBTX.Tx(ctx, func(ctx context.Context, tx DBTX) error {
+		return fn(ctx, &hookDB{DBTX: tx, hooks: db.hooks})
+	}, opts...)
+}
+
+func (db *hookDB) BeginTx(ctx context.Context, opts ...*sql.TxOptions) (DBTX, error) {
+	tx, err := db.DBTX.BeginTx(ctx, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &hookDB{DBTX: tx, hooks: db.hooks}, nil
+}
+
+func (db *hookDB) Prepare(ctx context.Context, format string) (*sql.Stmt, error) {
+	p, ok := db.DBTX.(Preparer)
+	if !ok {
+		return nil, errorf("prepare: unsupported db type")
+	}
+	return p.Prepare(ctx, format)
+}
+
+type BeforeInserter interface {
+	BeforeInsert(ctx context.Context) error
+}
+
+type AfterInserter interface {
+	AfterInsert(ctx context.Context) error
+}
+
+type BeforeUpdater interface {
+	BeforeUpdate(ctx context.Context) error
+}
+
+type AfterUpdater interface {
+	AfterUpdate(ctx context.Context) error
+}
+
+type BeforeDeleter interface {
+	BeforeDelete(ctx context.Context) error
+}
+
+type AfterDeleter interface {
+	AfterDelete(ctx context.Context) error
+}
+
+type AfterFinder interface {
+	AfterFind(ctx context.Context) error
+}
+
+func callBeforeHook(ctx context.Context, action Action, model interface{}) error {
+	switch action {
+	case actionInsert:
+		if h, ok := model.(BeforeInserter); ok {
+			return h.BeforeInsert(ctx)
+		}
+	case actionUpdate:
+		if h, ok := model.(BeforeUpdater); ok {
+			return h.BeforeUpdate(ctx)
+		}
+	case actionDelete:
+		if h, ok := model.(BeforeDeleter); ok {
+			return h.BeforeDelete(ctx)
+		}
+	}
+	return nil
+}
+
+func callAfterHook(ctx context.Context, action Action, model interface{}) error {
+	switch action {
+	case actionInsert:
+		if h, ok := model.(AfterInserter); ok {
+			return h.AfterInsert(ctx)
+		}
+	case actionUpdate:
+		if h, ok := model.(AfterUpdater); ok {
+			return h.AfterUpdate(ctx)
+		}
+	case actionDelete:
+		if h, ok := model.(AfterDeleter); ok {
+			return h.AfterDelete(ctx)
+		}
+	}
+	return nil
+}
+
+func callAfterFind(ctx context.Context, dst interface{}) error {
+	value := unrefValue(reflect.ValueOf(dst))
+	switch value.Kind() {
+	case reflect.Struct:
+		return callAfterFindValue(ctx, value)
+	case reflect.Slice:
+		for i := 0; i < value.Len(); i++ {
+			if err := callAfterFindValue(ctx, unrefValue(value.Index(i))); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func callAfterFindValue(ctx context.Context, value reflect.Value) error {
+	if !value.CanAddr() {
+		return nil
+	}
+	if h, ok := value.Addr().Interface().(AfterFinder); ok {
+		return h.AfterFind(ctx)
+	}
+	return nil
+}
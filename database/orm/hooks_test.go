@@ -0,0 +1,167 @@
+package orm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type recordHook struct {
+	name   string
+	events *[]string
+}
+
+func (h recordHook) BeforeQuery(ctx context.Context, e *QueryEvent) context.Context {
+	*h.events = append(*h.events, fmt.Sprintf("%s before %s: %s", h.name, e.Action, e.Template.Format))
+	return ctx
+}
+
+func (h recordHook) AfterQuery(ctx context.Context, e *QueryEvent) {
+	*h.events = append(*h.events, fmt.Sprintf("%s after %s: %s rows=%d err=%v", h.name, e.Action, e.Template.Format, e.RowsAffected, e.Err))
+}
+
+func TestWithHooks(t *testing.T) {
+	ctx := context.Background()
+	_, raw := openTestDB(t, `create table user (id integer primary key, name text)`)
+	var events []string
+	db := WithHooks(WithHooks(raw, recordHook{"a", &events}), recordHook{"b", &events})
+
+	if _, err := db.Exec(ctx, NewTemplate("insert into user(name) values (?)", "medivh")); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Tx(ctx, func(ctx context.Context, tx DBTX) error {
+		var names []string
+		return tx.Query(ctx, NewTemplate("select name from user"), &names)
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Query(ctx, NewTemplate("select name from nope"), &[]string{}); err == nil {
+		t.Fatal("expected error")
+	}
+	want := []string{
+		"a before exec: insert into user(name) values (?)",
+		"b before exec: insert into user(name) values (?)",
+		"b after exec: insert into user(name) values (?) rows=1 err=<nil>",
+		"a after exec: insert into user(name) values (?) rows=1 err=<nil>",
+		"a before query: select name from user",
+		"b before query: select name from user",
+		"b after query: select name from user rows=0 err=<nil>",
+		"a after query: select name from user rows=0 err=<nil>",
+		"a before query: select name from nope",
+		"b before query: select name from nope",
+		"b after query: select name from nope rows=0 err=no such table: nope",
+		"a after query: select name from nope rows=0 err=no such table: nope",
+	}
+	if !reflect.DeepEqual(events, want) {
+		t.Fatalf("got events:\n%q\nwant:\n%q", events, want)
+	}
+}
+
+func TestWithHooks_QueryRowsDuration(t *testing.T) {
+	ctx := context.Background()
+	_, raw := openTestDB(t, `create table user (id integer primary key)`, `insert into user(id) values (1), (2)`)
+	var events []*QueryEvent
+	db := WithHooks(raw, HookFunc(func(ctx context.Context, e *QueryEvent) {
+		events = append(events, e)
+	}))
+
+	rows, err := QueryRows(ctx, db, NewTemplate("select id from user"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 0 {
+		t.Fatalf("got %d events before the rows are closed", len(events))
+	}
+	const delay = 20 * time.Millisecond
+	if err := rows.Scan(func(scan func(...interface{}) error, abort func()) error {
+		time.Sleep(delay)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	rows.Close()
+	if len(events) != 1 {
+		t.Fatalf("got %d events, want 1", len(events))
+	}
+	if events[0].Duration < 2*delay {
+		t.Errorf("got duration %s, want at least %s", events[0].Duration, 2*delay)
+	}
+}
+
+type hookUser struct {
+	ID     int    `orm:"id"`
+	Name   string `orm:"name"`
+	events *[]string
+}
+
+func (u *hookUser) record(s string) error {
+	*u.events = append(*u.events, s)
+	if u.Name == "invalid" {
+		return errors.New("invalid name")
+	}
+	return nil
+}
+
+func (u *hookUser) BeforeInsert(ctx context.Context) error { return u.record("before insert") }
+func (u *hookUser) AfterInsert(ctx context.Context) error  { return u.record("after insert") }
+func (u *hookUser) BeforeUpdate(ctx context.Context) error { return u.record("before update") }
+func (u *hookUser) AfterUpdate(ctx context.Context) error  { return u.record("after update") }
+func (u *hookUser) BeforeDelete(ctx context.Context) error { return u.record("before delete") }
+func (u *hookUser) AfterDelete(ctx context.Context) error  { return u.record("after delete") }
+
+type findUser struct {
+	ID    int    `orm:"id"`
+	Found string `orm:"-"`
+}
+
+func (u *findUser) AfterFind(ctx context.Context) error {
+	u.Found = fmt.Sprintf("found %d", u.ID)
+	return nil
+}
+
+func TestModelHooks(t *testing.T) {
+	ctx := context.Background()
+	_, raw := openTestDB(t, `create table hookUser (id integer primary key, name text)`, `create table findUser (id integer primary key)`)
+	var events []string
+	db := WithHooks(raw, HookFunc(func(ctx context.Context, e *QueryEvent) {
+		events = append(events, e.Action)
+	}))
+	u := &hookUser{ID: 1, Name: "medivh", events: &events}
+
+	for _, b := range []*Builder{
+		New(&sqliteTestDialect{}).InsertModel(u, false),
+		New(&sqliteTestDialect{}).UpdateModel(u, false, "ID").Where("id = ?", 1),
+		New(&sqliteTestDialect{}).DeleteModel(u).Where("id = ?", 1),
+	} {
+		if _, err := b.Exec(ctx, db); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want := []string{"before insert", "exec", "after insert", "before update", "exec", "after update", "before delete", "exec", "after delete"}
+	if !reflect.DeepEqual(events, want) {
+		t.Fatalf("got events %q, want %q", events, want)
+	}
+
+	events = nil
+	u.Name = "invalid"
+	if _, err := New(&sqliteTestDialect{}).InsertModel(u, false).Exec(ctx, db); err == nil {
+		t.Fatal("expected error")
+	}
+	if want := []string{"before insert"}; !reflect.DeepEqual(events, want) {
+		t.Fatalf("got events %q, want %q", events, want)
+	}
+
+	if _, err := raw.Exec(ctx, NewTemplate("insert into findUser(id) values (1), (2)")); err != nil {
+		t.Fatal(err)
+	}
+	var users []findUser
+	if err := New(&sqliteTestDialect{}).SelectModel(findUser{}).OrderBy("id").Query(ctx, db, &users); err != nil {
+		t.Fatal(err)
+	}
+	if want := []findUser{{1, "found 1"}, {2, "found 2"}}; !reflect.DeepEqual(users, want) {
+		t.Fatalf("got users %v, want %v", users, want)
+	}
+}
@@ -0,0 +1,45 @@
+package orm
+
+import "context"
+
+type TableInfo struct {
+	Name        string
+	Columns     []ColumnInfo
+	Indexes     []IndexInfo
+	ForeignKeys []ForeignKeyInfo
+}
+
+type ColumnInfo struct {
+	Name       string
+	Type       string
+	Nullable   bool
+	PrimaryKey bool
+	Default    *string
+}
+
+type IndexInfo struct {
+	Name    string
+	Columns []string
+	Unique  bool
+}
+
+type ForeignKeyInfo struct {
+	Columns    []string
+	RefTable   string
+	RefColumns []string
+}
+
+// InspectDialect is implemented by dialects that can read the schema of an existing database.
+type InspectDialect interface {
+	Dialect
+	Inspect(ctx context.Context, db DBTX, tables ...string) ([]TableInfo, error)
+}
+
+// Inspect reads the metadata of tables, or of all tables if none is given.
+func Inspect(ctx context.Context, db DBTX, tables ...string) ([]TableInfo, error) {
+	d, ok := DialectOf(db).(InspectDialect)
+	if !ok {
+		return nil, errorf("inspect: unsupported dialect %T", DialectOf(db))
+	}
+	return d.Inspect(ctx, db, tables...)
+}
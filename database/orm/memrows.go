@@ -0,0 +1,153 @@
+package orm
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"strconv"
+	"sync"
+)
+
+// NewValueRows returns rows that yield values, scanned and bound like rows of a real query.
+func NewValueRows(dialect Dialect, columns []string, values [][]interface{}) (*Rows, error) {
+	valueRowsOnce.Do(func() {
+		valueRowsDB = sql.OpenDB(valueRows)
+	})
+	raw, err := valueRowsDB.Query(valueRows.put(valueSet{columns: columns, values: values}))
+	if err != nil {
+		return nil, err
+	}
+	return NewRows(dialect, raw), nil
+}
+
+// readAll reads the columns and values of the remaining rows and closes them.
+func (r *Rows) readAll() ([]string, [][]interface{}, error) {
+	defer r.Close()
+	columns, err := r.raw.Columns()
+	if err != nil {
+		return nil, nil, err
+	}
+	var values [][]interface{}
+	for r.raw.Next() {
+		row := make([]interface{}, len(columns))
+		pointers := make([]interface{}, len(columns))
+		for i := range row {
+			pointers[i] = &row[i]
+		}
+		if err := r.raw.Scan(pointers...); err != nil {
+			return nil, nil, err
+		}
+		values = append(values, row)
+	}
+	if err := r.raw.Err(); err != nil {
+		return nil, nil, err
+	}
+	return columns, values, r.Close()
+}
+
+var (
+	valueRowsOnce sync.Once
+	valueRowsDB   *sql.DB
+	valueRows     = &valueConnector{sets: map[string]valueSet{}}
+)
+
+type valueSet struct {
+	columns []string
+	values  [][]interface{}
+}
+
+// valueConnector serves value sets to database/sql by keys passed as queries.
+type valueConnector struct {
+	mu   sync.Mutex
+	next int
+	sets map[string]valueSet
+}
+
+func (c *valueConnector) put(set valueSet) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.next++
+	key := strconv.Itoa(c.next)
+	c.sets[key] = set
+	return key
+}
+
+func (c *valueConnector) take(key string) (valueSet, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	set, ok := c.sets[key]
+	delete(c.sets, key)
+	return set, ok
+}
+
+func (c *valueConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	return valueConn{c}, nil
+}
+
+func (c *valueConnector) Driver() driver.Driver {
+	return valueDriver{}
+}
+
+type valueDriver struct{}
+
+func (valueDriver) Open(name string) (driver.Conn, error) {
+	return nil, errorf("value rows: open is not supported")
+}
+
+type valueConn struct {
+	connector *valueConnector
+}
+
+func (c valueConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	set, ok := c.connector.take(query)
+	if !ok {
+		return nil, errorf("value rows: not found rows")
+	}
+	return &valueDriverRows{set: set}, nil
+}
+
+func (c valueConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, errorf("value rows: prepare is not supported")
+}
+
+func (c valueConn) Close() error {
+	return nil
+}
+
+func (c valueConn) Begin() (driver.Tx, error) {
+	return nil, errorf("value rows: begin is not supported")
+}
+
+type valueDriverRows struct {
+	set   valueSet
+	index int
+}
+
+func (r *valueDriverRows) Columns() []string {
+	return r.set.columns
+}
+
+func (r *valueDriverRows) Close() error {
+	return nil
+}
+
+func (r *valueDriverRows) Next(dest []driver.Value) error {
+	if r.index >= len(r.set.values) {
+		return io.EOF
+	}
+	row := r.set.values[r.index]
+	r.index++
+	for i := range dest {
+		if i >= len(row) {
+			dest[i] = nil
+			continue
+		}
+		v, err := driver.DefaultParameterConverter.ConvertValue(row[i])
+		if err != nil {
+			return err
+		}
+		dest[i] = v
+	}
+	return nil
+}
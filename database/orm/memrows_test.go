@@ -0,0 +1,18 @@
+package orm
+
+import "fmt"
+
+func ExampleNewValueRows() {
+	type User struct {
+		ID   int    `orm:"id"`
+		Name string `orm:"name"`
+	}
+	rows, err := NewValueRows(&TestDialect{}, []string{"id", "name"}, [][]interface{}{{1, "Medivh"}, {2, "Jason"}})
+	if err != nil {
+		panic(err)
+	}
+	var users []User
+	fmt.Println(rows.Bind(&users), users)
+	// output:
+	// <nil> [{1 Medivh} {2 Jason}]
+}
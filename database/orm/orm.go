@@ -13,6 +13,7 @@ var (
 	ErrRequireSliceType   = errorf("require slice type")
 	ErrRequireStructType  = errorf("require struct type")
 	ErrCannotSetValue     = errorf("can not set value")
+	ErrNestedTxOptions    = errorf("tx: options of nested transaction are not supported")
 )
 
 var TagKey = "orm"
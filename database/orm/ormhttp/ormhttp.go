@@ -0,0 +1,22 @@
+// Package ormhttp serves orm database information over http.
+package ormhttp
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/medivhyang/golib/database/orm"
+)
+
+// StatsHandler serves the stats of db as json.
+func StatsHandler(db orm.DBTX) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s, err := orm.Stats(db)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(orm.StatsFields(s))
+	})
+}
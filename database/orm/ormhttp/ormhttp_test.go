@@ -0,0 +1,35 @@
+package ormhttp
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http/httptest"
+
+	"github.com/medivhyang/golib/database/orm"
+	"github.com/medivhyang/golib/database/orm/dialect/sqlite3"
+	"github.com/medivhyang/golib/database/orm/ormtest"
+)
+
+func ExampleStatsHandler() {
+	raw, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		panic(err)
+	}
+	defer raw.Close()
+	raw.SetMaxOpenConns(2)
+
+	w := httptest.NewRecorder()
+	StatsHandler(orm.NewDB(&sqlite3.Dialect{}, raw)).ServeHTTP(w, httptest.NewRequest("GET", "/stats", nil))
+	fmt.Println(w.Code, w.Header().Get("Content-Type"))
+	fmt.Print(w.Body.String())
+
+	w = httptest.NewRecorder()
+	StatsHandler(ormtest.New(ormtest.Dialect{})).ServeHTTP(w, httptest.NewRequest("GET", "/stats", nil))
+	fmt.Println(w.Code)
+	fmt.Print(w.Body.String())
+	// output:
+	// 200 application/json
+	// {"idle":0,"in_use":0,"max_idle_closed":0,"max_idle_time_closed":0,"max_lifetime_closed":0,"max_open_connections":2,"open_connections":0,"wait_count":0,"wait_duration":"0s"}
+	// 500
+	// orm: stats: require *sql.DB
+}
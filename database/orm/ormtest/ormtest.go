@@ -0,0 +1,330 @@
+// Package ormtest provides a fake orm.DBTX that records queries and answers them from expectations.
+package ormtest
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/medivhyang/golib/database/orm"
+)
+
+const (
+	ActionQuery    = "query"
+	ActionExec     = "exec"
+	ActionBegin    = "begin"
+	ActionCommit   = "commit"
+	ActionRollback = "rollback"
+)
+
+type Call struct {
+	Action   string
+	Template orm.Template
+}
+
+// AnyArg matches any argument in Expectation.WithArgs.
+var AnyArg = anyArg{}
+
+type anyArg struct{}
+
+type Rows struct {
+	columns []string
+	values  [][]interface{}
+}
+
+func NewRows(columns ...string) *Rows {
+	return &Rows{columns: columns}
+}
+
+func (r *Rows) AddRow(values ...interface{}) *Rows {
+	r.values = append(r.values, values)
+	return r
+}
+
+type Expectation struct {
+	action  string
+	sql     string
+	pattern *regexp.Regexp
+	args    []interface{}
+	hasArgs bool
+	rows    *Rows
+	result  result
+	err     error
+	met     bool
+}
+
+func (e *Expectation) WithArgs(args ...interface{}) *Expectation {
+	e.args = args
+	e.hasArgs = true
+	return e
+}
+
+func (e *Expectation) WillReturnRows(rows *Rows) *Expectation {
+	e.rows = rows
+	return e
+}
+
+func (e *Expectation) WillReturnResult(lastInsertID int64, rowsAffected int64) *Expectation {
+	e.result = result{lastInsertID: lastInsertID, rowsAffected: rowsAffected}
+	return e
+}
+
+func (e *Expectation) WillReturnError(err error) *Expectation {
+	e.err = err
+	return e
+}
+
+func (e *Expectation) String() string {
+	switch {
+	case e.pattern != nil:
+		return fmt.Sprintf("%s matching %q", e.action, e.pattern.String())
+	case e.sql != "":
+		return fmt.Sprintf("%s %q", e.action, e.sql)
+	default:
+		return e.action
+	}
+}
+
+func (e *Expectation) match(action string, t orm.Template) error {
+	if e.action != action {
+		return fmt.Errorf("ormtest: %s, expect %s", describe(action, t), e)
+	}
+	switch {
+	case e.pattern != nil:
+		if !e.pattern.MatchString(t.Format) {
+			return fmt.Errorf("ormtest: %s %q does not match %q", action, t.Format, e.pattern.String())
+		}
+	case e.sql != "":
+		if strings.TrimSpace(e.sql) != strings.TrimSpace(t.Format) {
+			return fmt.Errorf("ormtest: %s %q, expect %q", action, t.Format, e.sql)
+		}
+	}
+	if !e.hasArgs {
+		return nil
+	}
+	if len(e.args) != len(t.Values) {
+		return fmt.Errorf("ormtest: %s %s, expect args %#v", action, t.String(), e.args)
+	}
+	for i, arg := range e.args {
+		if arg == AnyArg {
+			continue
+		}
+		if !reflect.DeepEqual(arg, t.Values[i]) {
+			return fmt.Errorf("ormtest: %s %s, expect args %#v", action, t.String(), e.args)
+		}
+	}
+	return nil
+}
+
+func describe(action string, t orm.Template) string {
+	if t.Format == "" {
+		return action
+	}
+	return action + " " + t.String()
+}
+
+type result struct {
+	lastInsertID int64
+	rowsAffected int64
+}
+
+func (r result) LastInsertId() (int64, error) {
+	return r.lastInsertID, nil
+}
+
+func (r result) RowsAffected() (int64, error) {
+	return r.rowsAffected, nil
+}
+
+// DB is a fake orm.DBTX, every call is recorded and must match the next unmet expectation in order.
+type DB struct {
+	*state
+	depth int
+}
+
+type state struct {
+	mu           sync.Mutex
+	dialect      orm.Dialect
+	calls        []Call
+	expectations []*Expectation
+}
+
+// New returns a fake DB using dialect, or the default dialect if dialect is nil.
+func New(dialect orm.Dialect) *DB {
+	if dialect == nil {
+		if d, ok := orm.LookupDialect(orm.DefaultDialectName); ok {
+			dialect = d
+		} else {
+			dialect = Dialect{}
+		}
+	}
+	return &DB{state: &state{dialect: dialect}}
+}
+
+func (db *DB) ExpectQuery(sql string) *Expectation {
+	return db.expect(&Expectation{action: ActionQuery, sql: sql})
+}
+
+func (db *DB) ExpectQueryRegexp(pattern string) *Expectation {
+	return db.expect(&Expectation{action: ActionQuery, pattern: regexp.MustCompile(pattern)})
+}
+
+func (db *DB) ExpectExec(sql string) *Expectation {
+	return db.expect(&Expectation{action: ActionExec, sql: sql})
+}
+
+func (db *DB) ExpectExecRegexp(pattern string) *Expectation {
+	return db.expect(&Expectation{action: ActionExec, pattern: regexp.MustCompile(pattern)})
+}
+
+func (db *DB) ExpectBegin() *Expectation {
+	return db.expect(&Expectation{action: ActionBegin})
+}
+
+func (db *DB) ExpectCommit() *Expectation {
+	return db.expect(&Expectation{action: ActionCommit})
+}
+
+func (db *DB) ExpectRollback() *Expectation {
+	return db.expect(&Expectation{action: ActionRollback})
+}
+
+func (db *DB) expect(e *Expectation) *Expectation {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.expectations = append(db.expectations, e)
+	return e
+}
+
+// Calls returns the recorded calls in order.
+func (db *DB) Calls() []Call {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	return append([]Call{}, db.calls...)
+}
+
+// ExpectationsWereMet returns an error listing the expectations that weren't met.
+func (db *DB) ExpectationsWereMet() error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	var unmet []string
+	for _, e := range db.expectations {
+		if !e.met {
+			unmet = append(unmet, e.String())
+		}
+	}
+	if len(unmet) > 0 {
+		return fmt.Errorf("ormtest: unmet expectations: %s", strings.Join(unmet, ", "))
+	}
+	return nil
+}
+
+func (db *DB) call(action string, t orm.Template) (*Expectation, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.calls = append(db.calls, Call{Action: action, Template: t})
+	for _, e := range db.expectations {
+		if e.met {
+			continue
+		}
+		if err := e.match(action, t); err != nil {
+			return nil, err
+		}
+		e.met = true
+		return e, e.err
+	}
+	return nil, fmt.Errorf("ormtest: unexpected %s", describe(action, t))
+}
+
+func (db *DB) Dialect() orm.Dialect {
+	return db.dialect
+}
+
+func (db *DB) Query(ctx context.Context, t orm.Template, i interface{}) error {
+	rows, err := db.QueryRows(ctx, t)
+	if err != nil {
+		return err
+	}
+	return rows.Bind(i)
+}
+
+func (db *DB) QueryRows(ctx context.Context, t orm.Template) (*orm.Rows, error) {
+	e, err := db.call(ActionQuery, t)
+	if err != nil {
+		return nil, err
+	}
+	rows := e.rows
+	if rows == nil {
+		rows = NewRows()
+	}
+	return orm.NewValueRows(db.dialect, rows.columns, rows.values)
+}
+
+func (db *DB) Exec(ctx context.Context, t orm.Template) (sql.Result, error) {
+	e, err := db.call(ActionExec, t)
+	if err != nil {
+		return nil, err
+	}
+	return e.result, nil
+}
+
+func (db *DB) Tx(ctx context.Context, fn func(ctx context.Context, tx orm.DBTX) error, opts ...*sql.TxOptions) (err error) {
+	tx, err := db.BeginTx(ctx, opts...)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if x := recover(); x != nil {
+			tx.Rollback()
+			panic(x)
+		}
+	}()
+	if err := fn(ctx, tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+func (db *DB) BeginTx(ctx context.Context, opts ...*sql.TxOptions) (orm.DBTX, error) {
+	if _, err := db.call(ActionBegin, orm.Template{}); err != nil {
+		return nil, err
+	}
+	return &DB{state: db.state, depth: db.depth + 1}, nil
+}
+
+// InTx reports whether db is a transaction begun by BeginTx or Tx.
+func (db *DB) InTx() bool {
+	return db.depth > 0
+}
+
+func (db *DB) Rollback() error {
+	if db.depth == 0 {
+		return nil
+	}
+	_, err := db.call(ActionRollback, orm.Template{})
+	return err
+}
+
+func (db *DB) Commit() error {
+	if db.depth == 0 {
+		return nil
+	}
+	_, err := db.call(ActionCommit, orm.Template{})
+	return err
+}
+
+// Dialect is used when no dialect is given and no default dialect is registered.
+type Dialect struct{}
+
+func (Dialect) MappingType(rt reflect.Type) string {
+	return ""
+}
+
+func (Dialect) Quote(s string) string {
+	return fmt.Sprintf("\"%s\"", s)
+}
@@ -0,0 +1,71 @@
+package ormtest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/medivhyang/golib/database/orm"
+)
+
+type user struct {
+	ID   int    `orm:"id"`
+	Name string `orm:"name"`
+}
+
+func (user) Table() string {
+	return "user"
+}
+
+func ExampleDB() {
+	ctx := context.Background()
+	db := New(Dialect{})
+	db.ExpectQuery(`select "id","name" from "user" where ("id" > ?)`).
+		WithArgs(1).
+		WillReturnRows(NewRows("id", "name").AddRow(2, "Medivh").AddRow(3, "Jason"))
+	db.ExpectBegin()
+	db.ExpectExecRegexp(`^update "user" set`).WillReturnResult(0, 2)
+	db.ExpectRollback()
+
+	var users []user
+	err := orm.New(db.Dialect()).SelectModel(user{}).WhereExpr(orm.Col("id").Gt(1)).Query(ctx, db, &users)
+	fmt.Println(users, err)
+
+	err = db.Tx(ctx, func(ctx context.Context, tx orm.DBTX) error {
+		result, err := orm.New(orm.DialectOf(tx)).Update("user", map[string]interface{}{"name": "x"}).Exec(ctx, tx)
+		if err != nil {
+			return err
+		}
+		n, _ := result.RowsAffected()
+		fmt.Println(n)
+		return errors.New("abort")
+	})
+	fmt.Println(err)
+	fmt.Println(db.ExpectationsWereMet())
+	for _, c := range db.Calls() {
+		fmt.Println(strings.TrimSpace(c.Action + " " + c.Template.Format))
+	}
+	// output:
+	// [{2 Medivh} {3 Jason}] <nil>
+	// 2
+	// abort
+	// <nil>
+	// query select "id","name" from "user" where ("id" > ?)
+	// begin
+	// exec update "user" set "name" = ?
+	// rollback
+}
+
+func ExampleDB_ExpectationsWereMet() {
+	db := New(Dialect{})
+	db.ExpectExec("delete from user")
+	db.ExpectCommit()
+
+	_, err := db.Exec(context.Background(), orm.NewTemplate("delete from role"))
+	fmt.Println(err)
+	fmt.Println(db.ExpectationsWereMet())
+	// output:
+	// ormtest: exec "delete from role", expect "delete from user"
+	// ormtest: unmet expectations: exec "delete from user", commit
+}
@@ -0,0 +1,120 @@
+package orm
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+type PoolOptions struct {
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+	ConnMaxIdleTime time.Duration
+	// PingAttempts is the number of pings before giving up at startup, defaults to 1.
+	PingAttempts int
+	// PingBackoff is the delay before the first retry, doubled on every attempt, defaults to 100ms.
+	PingBackoff time.Duration
+}
+
+func (o PoolOptions) apply(raw *sql.DB) {
+	if o.MaxOpenConns > 0 {
+		raw.SetMaxOpenConns(o.MaxOpenConns)
+	}
+	if o.MaxIdleConns != 0 {
+		raw.SetMaxIdleConns(o.MaxIdleConns)
+	}
+	if o.ConnMaxLifetime > 0 {
+		raw.SetConnMaxLifetime(o.ConnMaxLifetime)
+	}
+	if o.ConnMaxIdleTime > 0 {
+		raw.SetConnMaxIdleTime(o.ConnMaxIdleTime)
+	}
+}
+
+// ping pings raw until it succeeds, the attempts run out or ctx is done.
+func ping(ctx context.Context, raw *sql.DB, o PoolOptions) error {
+	attempts := o.PingAttempts
+	if attempts <= 0 {
+		attempts = 1
+	}
+	backoff := o.PingBackoff
+	if backoff <= 0 {
+		backoff = 100 * time.Millisecond
+	}
+	for attempt := 1; ; attempt++ {
+		err := raw.PingContext(ctx)
+		if err == nil || attempt >= attempts {
+			return err
+		}
+		debugf("ping: retry attempt %d after %s: %v", attempt, backoff, err)
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+		backoff *= 2
+	}
+}
+
+type unwrapper interface {
+	Unwrap() DBTX
+}
+
+// RawDB returns the *sql.DB under db and its wrappers, it's false in transactions.
+func RawDB(dbtx DBTX) (*sql.DB, bool) {
+	for {
+		switch v := dbtx.(type) {
+		case *db:
+			raw, ok := v.raw.(*sql.DB)
+			return raw, ok
+		case unwrapper:
+			dbtx = v.Unwrap()
+		default:
+			return nil, false
+		}
+	}
+}
+
+func Stats(db DBTX) (sql.DBStats, error) {
+	raw, ok := RawDB(db)
+	if !ok {
+		return sql.DBStats{}, errorf("stats: require *sql.DB")
+	}
+	return raw.Stats(), nil
+}
+
+// StatsFields returns stats as fields for structured logging, e.g. log.Info("db stats", orm.StatsFields(s)).
+func StatsFields(s sql.DBStats) map[string]interface{} {
+	return map[string]interface{}{
+		"max_open_connections": s.MaxOpenConnections,
+		"open_connections":     s.OpenConnections,
+		"in_use":               s.InUse,
+		"idle":                 s.Idle,
+		"wait_count":           s.WaitCount,
+		"wait_duration":        s.WaitDuration.String(),
+		"max_idle_closed":      s.MaxIdleClosed,
+		"max_idle_time_closed": s.MaxIdleTimeClosed,
+		"max_lifetime_closed":  s.MaxLifetimeClosed,
+	}
+}
+
+// ReportStats calls report with the stats of db every interval until ctx is done.
+func ReportStats(ctx context.Context, db DBTX, interval time.Duration, report func(s sql.DBStats)) error {
+	raw, ok := RawDB(db)
+	if !ok {
+		return errorf("stats: require *sql.DB")
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			report(raw.Stats())
+		}
+	}
+}
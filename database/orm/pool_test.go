@@ -0,0 +1,134 @@
+package orm
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+func ExampleStatsFields() {
+	fields := StatsFields(sql.DBStats{MaxOpenConnections: 10, OpenConnections: 3, InUse: 2, Idle: 1, WaitDuration: time.Second})
+	for _, k := range sortedKeys(fields) {
+		fmt.Println(k, fields[k])
+	}
+	// output:
+	// idle 1
+	// in_use 2
+	// max_idle_closed 0
+	// max_idle_time_closed 0
+	// max_lifetime_closed 0
+	// max_open_connections 10
+	// open_connections 3
+	// wait_count 0
+	// wait_duration 1s
+}
+
+// pingTestDriver fails to open the first fails connections of a data source name.
+type pingTestDriver struct {
+	mu    sync.Mutex
+	fails map[string]int
+	opens map[string]int
+}
+
+var testPingDriver = &pingTestDriver{fails: map[string]int{}, opens: map[string]int{}}
+
+func init() {
+	sql.Register("orm_ping_test", testPingDriver)
+	RegisterDialect("orm_ping_test", &TestDialect{})
+}
+
+func (d *pingTestDriver) setFails(name string, n int) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.fails[name] = n
+	d.opens[name] = 0
+}
+
+func (d *pingTestDriver) openCount(name string) int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.opens[name]
+}
+
+func (d *pingTestDriver) Open(name string) (driver.Conn, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.opens[name]++
+	if d.fails[name] > 0 {
+		d.fails[name]--
+		return nil, errors.New("connection refused")
+	}
+	return pingTestConn{}, nil
+}
+
+type pingTestConn struct{}
+
+func (pingTestConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, errors.New("not supported")
+}
+
+func (pingTestConn) Close() error {
+	return nil
+}
+
+func (pingTestConn) Begin() (driver.Tx, error) {
+	return nil, errors.New("not supported")
+}
+
+func TestOpenDB(t *testing.T) {
+	testPingDriver.setFails("options", 0)
+	db, err := OpenDB("orm_ping_test", "options", PoolOptions{MaxOpenConns: 3, MaxIdleConns: -1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, _ := RawDB(db)
+	defer raw.Close()
+	s, err := Stats(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.MaxOpenConnections != 3 || s.Idle != 0 {
+		t.Fatalf("got max open %d idle %d, want 3 and 0", s.MaxOpenConnections, s.Idle)
+	}
+
+	testPingDriver.setFails("retry", 2)
+	db, err = OpenDB("orm_ping_test", "retry", PoolOptions{PingAttempts: 3, PingBackoff: time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, _ = RawDB(db)
+	raw.Close()
+	if n := testPingDriver.openCount("retry"); n != 3 {
+		t.Fatalf("got %d opens, want 3", n)
+	}
+
+	testPingDriver.setFails("exhausted", 3)
+	if _, err := OpenDB("orm_ping_test", "exhausted", PoolOptions{PingAttempts: 2, PingBackoff: time.Millisecond}); err == nil {
+		t.Fatal("got nil error after the attempts run out")
+	}
+	if n := testPingDriver.openCount("exhausted"); n != 2 {
+		t.Fatalf("got %d opens, want 2", n)
+	}
+}
+
+func TestOpenDBContext_cancel(t *testing.T) {
+	testPingDriver.setFails("cancel", 10)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := OpenDBContext(ctx, "orm_ping_test", "cancel", PoolOptions{PingAttempts: 10, PingBackoff: time.Hour})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("got error %v, want %v", err, context.DeadlineExceeded)
+	}
+	if d := time.Since(start); d > time.Second {
+		t.Fatalf("got retry stopped after %s, want it stopped by ctx", d)
+	}
+	if n := testPingDriver.openCount("cancel"); n != 1 {
+		t.Fatalf("got %d opens, want 1", n)
+	}
+}
@@ -0,0 +1,440 @@
+package orm
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+type RelationKind string
+
+const (
+	RelationBelongsTo  RelationKind = "belongs_to"
+	RelationHasMany    RelationKind = "has_many"
+	RelationManyToMany RelationKind = "many_to_many"
+)
+
+var ErrNotFoundRelation = errorf("not found relation")
+
+// Relation describes a field that references other models, declared by tag options, e.g.
+//
+//	User   *User   `orm:",belongs_to,foreign_key=user_id"`
+//	Orders []Order `orm:",has_many,foreign_key=user_id"`
+//	Tags   []Tag   `orm:",many_to_many=user_tags,join_foreign_key=user_id,join_references=tag_id"`
+type Relation struct {
+	Field            string
+	Kind             RelationKind
+	Type             reflect.Type
+	ForeignKey       string
+	References       string
+	JoinTable        string
+	JoinForeignKey   string
+	JoinReferences   string
+	TargetReferences string
+}
+
+func ParseRelations(model interface{}) ([]Relation, error) {
+	rt := reflect.TypeOf(model)
+	for rt.Kind() == reflect.Ptr {
+		rt = rt.Elem()
+	}
+	if rt.Kind() != reflect.Struct {
+		return nil, ErrRequireStructType
+	}
+	var result []Relation
+	for i := 0; i < rt.NumField(); i++ {
+		sf := rt.Field(i)
+		if !isRelationField(sf) {
+			continue
+		}
+		r, err := ParseRelation(rt, sf)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, r)
+	}
+	return result, nil
+}
+
+func ParseRelation(owner reflect.Type, sf reflect.StructField) (Relation, error) {
+	_, options := parseTag(sf)
+	r := Relation{
+		Field:            sf.Name,
+		ForeignKey:       options["foreign_key"],
+		References:       options["references"],
+		JoinForeignKey:   options["join_foreign_key"],
+		JoinReferences:   options["join_references"],
+		TargetReferences: options["target_references"],
+	}
+	target := sf.Type
+	for target.Kind() == reflect.Ptr || target.Kind() == reflect.Slice {
+		target = target.Elem()
+	}
+	if target.Kind() != reflect.Struct {
+		return Relation{}, errorf("relation %s: %v", sf.Name, ErrRequireStructType)
+	}
+	r.Type = target
+	switch {
+	case hasKey(options, string(RelationBelongsTo)):
+		r.Kind = RelationBelongsTo
+		if r.ForeignKey == "" {
+			r.ForeignKey = toCase(caseSnake, sf.Name) + "_id"
+		}
+		if r.References == "" {
+			r.References = "id"
+		}
+	case hasKey(options, string(RelationHasMany)):
+		r.Kind = RelationHasMany
+		if sf.Type.Kind() != reflect.Slice {
+			return Relation{}, errorf("relation %s: %v", sf.Name, ErrRequireSliceType)
+		}
+		if r.ForeignKey == "" {
+			r.ForeignKey = toCase(caseSnake, owner.Name()) + "_id"
+		}
+		if r.References == "" {
+			r.References = "id"
+		}
+	case hasKey(options, string(RelationManyToMany)):
+		r.Kind = RelationManyToMany
+		if sf.Type.Kind() != reflect.Slice {
+			return Relation{}, errorf("relation %s: %v", sf.Name, ErrRequireSliceType)
+		}
+		r.JoinTable = options[string(RelationManyToMany)]
+		if r.JoinTable == "" {
+			return Relation{}, errorf("relation %s: require join table", sf.Name)
+		}
+		if r.References == "" {
+			r.References = "id"
+		}
+		if r.JoinForeignKey == "" {
+			r.JoinForeignKey = toCase(caseSnake, owner.Name()) + "_id"
+		}
+		if r.JoinReferences == "" {
+			r.JoinReferences = toCase(caseSnake, target.Name()) + "_id"
+		}
+		if r.TargetReferences == "" {
+			r.TargetReferences = "id"
+		}
+	}
+	return r, nil
+}
+
+func isRelationField(sf reflect.StructField) bool {
+	_, options := parseTag(sf)
+	return hasKey(options, string(RelationBelongsTo)) ||
+		hasKey(options, string(RelationHasMany)) ||
+		hasKey(options, string(RelationManyToMany))
+}
+
+func hasKey(m map[string]string, k string) bool {
+	_, ok := m[k]
+	return ok
+}
+
+type preloadTree map[string]preloadTree
+
+func newPreloadTree(paths ...string) preloadTree {
+	root := preloadTree{}
+	for _, path := range paths {
+		node := root
+		for _, name := range strings.Split(path, ".") {
+			if name == "" {
+				continue
+			}
+			if node[name] == nil {
+				node[name] = preloadTree{}
+			}
+			node = node[name]
+		}
+	}
+	return root
+}
+
+func preload(ctx context.Context, db DBTX, dialect Dialect, dst interface{}, paths ...string) error {
+	value := unrefValue(reflect.ValueOf(dst))
+	var owners []reflect.Value
+	switch value.Kind() {
+	case reflect.Struct:
+		owners = append(owners, value)
+	case reflect.Slice:
+		for i := 0; i < value.Len(); i++ {
+			owners = append(owners, unrefValue(value.Index(i)))
+		}
+	default:
+		return ErrRequireStructType
+	}
+	return newPreloadTree(paths...).load(ctx, db, dialect, owners)
+}
+
+func (tree preloadTree) load(ctx context.Context, db DBTX, dialect Dialect, owners []reflect.Value) error {
+	if len(owners) == 0 || len(tree) == 0 {
+		return nil
+	}
+	ownerType := owners[0].Type()
+	names := make([]string, 0, len(tree))
+	for name := range tree {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		sub := tree[name]
+		sf, ok := ownerType.FieldByName(name)
+		if !ok || !isRelationField(sf) {
+			return errorf("preload %s.%s: %v", ownerType.Name(), name, ErrNotFoundRelation)
+		}
+		r, err := ParseRelation(ownerType, sf)
+		if err != nil {
+			return err
+		}
+		var children []reflect.Value
+		switch r.Kind {
+		case RelationBelongsTo:
+			children, err = loadBelongsTo(ctx, db, dialect, owners, r)
+		case RelationHasMany:
+			children, err = loadHasMany(ctx, db, dialect, owners, r)
+		case RelationManyToMany:
+			children, err = loadManyToMany(ctx, db, dialect, owners, r)
+		}
+		if err != nil {
+			return err
+		}
+		if err := sub.load(ctx, db, dialect, children); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func loadBelongsTo(ctx context.Context, db DBTX, dialect Dialect, owners []reflect.Value, r Relation) ([]reflect.Value, error) {
+	keys, err := collectColumnValues(dialect, owners, r.ForeignKey)
+	if err != nil || len(keys) == 0 {
+		return nil, err
+	}
+	targets, err := queryRelated(ctx, db, dialect, r.Type, r.References, keys)
+	if err != nil {
+		return nil, err
+	}
+	targetIndex, err := columnIndex(dialect, r.Type, r.References)
+	if err != nil {
+		return nil, err
+	}
+	ownerIndex, err := columnIndex(dialect, owners[0].Type(), r.ForeignKey)
+	if err != nil {
+		return nil, err
+	}
+	index := map[string]reflect.Value{}
+	for i := 0; i < targets.Len(); i++ {
+		index[columnKey(targets.Index(i), targetIndex)] = targets.Index(i)
+	}
+	var children []reflect.Value
+	for _, owner := range owners {
+		target, ok := index[columnKey(owner, ownerIndex)]
+		if !ok {
+			continue
+		}
+		field := owner.FieldByName(r.Field)
+		if field.Kind() == reflect.Ptr {
+			field.Set(reflect.New(r.Type))
+			field.Elem().Set(target)
+			children = append(children, field.Elem())
+		} else {
+			field.Set(target)
+			children = append(children, field)
+		}
+	}
+	return children, nil
+}
+
+func loadHasMany(ctx context.Context, db DBTX, dialect Dialect, owners []reflect.Value, r Relation) ([]reflect.Value, error) {
+	keys, err := collectColumnValues(dialect, owners, r.References)
+	if err != nil || len(keys) == 0 {
+		return nil, err
+	}
+	targets, err := queryRelated(ctx, db, dialect, r.Type, r.ForeignKey, keys)
+	if err != nil {
+		return nil, err
+	}
+	targetIndex, err := columnIndex(dialect, r.Type, r.ForeignKey)
+	if err != nil {
+		return nil, err
+	}
+	groups := map[string][]reflect.Value{}
+	for i := 0; i < targets.Len(); i++ {
+		k := columnKey(targets.Index(i), targetIndex)
+		groups[k] = append(groups[k], targets.Index(i))
+	}
+	return assignSlices(dialect, owners, r, groups)
+}
+
+func loadManyToMany(ctx context.Context, db DBTX, dialect Dialect, owners []reflect.Value, r Relation) ([]reflect.Value, error) {
+	keys, err := collectColumnValues(dialect, owners, r.References)
+	if err != nil || len(keys) == 0 {
+		return nil, err
+	}
+	var links []map[string]interface{}
+	for _, chunk := range chunkKeys(dialect, keys) {
+		var chunkLinks []map[string]interface{}
+		if err := New(dialect).
+			Select(r.JoinTable, r.JoinForeignKey, r.JoinReferences).
+			WhereIn(r.JoinForeignKey, chunk...).
+			Query(ctx, db, &chunkLinks); err != nil {
+			return nil, err
+		}
+		links = append(links, chunkLinks...)
+	}
+	if len(links) == 0 {
+		return nil, nil
+	}
+	targetKeys := make([]interface{}, 0, len(links))
+	seen := map[string]bool{}
+	for _, link := range links {
+		k := formatKey(link[r.JoinReferences])
+		if !seen[k] {
+			seen[k] = true
+			targetKeys = append(targetKeys, link[r.JoinReferences])
+		}
+	}
+	targets, err := queryRelated(ctx, db, dialect, r.Type, r.TargetReferences, targetKeys)
+	if err != nil {
+		return nil, err
+	}
+	targetIndex, err := columnIndex(dialect, r.Type, r.TargetReferences)
+	if err != nil {
+		return nil, err
+	}
+	index := map[string]reflect.Value{}
+	for i := 0; i < targets.Len(); i++ {
+		index[columnKey(targets.Index(i), targetIndex)] = targets.Index(i)
+	}
+	groups := map[string][]reflect.Value{}
+	for _, link := range links {
+		target, ok := index[formatKey(link[r.JoinReferences])]
+		if !ok {
+			continue
+		}
+		k := formatKey(link[r.JoinForeignKey])
+		groups[k] = append(groups[k], target)
+	}
+	return assignSlices(dialect, owners, r, groups)
+}
+
+func queryRelated(ctx context.Context, db DBTX, dialect Dialect, rt reflect.Type, column string, keys []interface{}) (reflect.Value, error) {
+	result := reflect.MakeSlice(reflect.SliceOf(rt), 0, len(keys))
+	for _, chunk := range chunkKeys(dialect, keys) {
+		slice := reflect.New(reflect.SliceOf(rt))
+		if err := New(dialect).
+			SelectModel(reflect.New(rt).Interface()).
+			WhereIn(column, chunk...).
+			Query(ctx, db, slice.Interface()); err != nil {
+			return reflect.Value{}, err
+		}
+		result = reflect.AppendSlice(result, slice.Elem())
+	}
+	return result, nil
+}
+
+// chunkKeys splits keys into chunks that fit the max params of dialect in an in list.
+func chunkKeys(dialect Dialect, keys []interface{}) [][]interface{} {
+	size := MaxParams(dialect)
+	var result [][]interface{}
+	for len(keys) > size {
+		result = append(result, keys[:size:size])
+		keys = keys[size:]
+	}
+	return append(result, keys)
+}
+
+func assignSlices(dialect Dialect, owners []reflect.Value, r Relation, groups map[string][]reflect.Value) ([]reflect.Value, error) {
+	ownerIndex, err := columnIndex(dialect, owners[0].Type(), r.References)
+	if err != nil {
+		return nil, err
+	}
+	var children []reflect.Value
+	for _, owner := range owners {
+		field := owner.FieldByName(r.Field)
+		items := groups[columnKey(owner, ownerIndex)]
+		slice := reflect.MakeSlice(field.Type(), 0, len(items))
+		for _, item := range items {
+			if field.Type().Elem().Kind() == reflect.Ptr {
+				p := reflect.New(r.Type)
+				p.Elem().Set(item)
+				slice = reflect.Append(slice, p)
+			} else {
+				slice = reflect.Append(slice, item)
+			}
+		}
+		field.Set(slice)
+		for i := 0; i < slice.Len(); i++ {
+			children = append(children, unrefValue(slice.Index(i)))
+		}
+	}
+	return children, nil
+}
+
+func collectColumnValues(dialect Dialect, owners []reflect.Value, column string) ([]interface{}, error) {
+	if len(owners) == 0 {
+		return nil, nil
+	}
+	index, err := columnIndex(dialect, owners[0].Type(), column)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]interface{}, 0, len(owners))
+	seen := map[string]bool{}
+	for _, owner := range owners {
+		field, ok := fieldValue(owner, index, true)
+		if !ok || field.IsZero() {
+			continue
+		}
+		v := unrefValue(field).Interface()
+		k := formatKey(v)
+		if !seen[k] {
+			seen[k] = true
+			result = append(result, v)
+		}
+	}
+	return result, nil
+}
+
+// columnKey returns the key of the field of owner at index, which is resolved once per type by columnIndex.
+func columnKey(owner reflect.Value, index []int) string {
+	field, ok := fieldValue(owner, index, true)
+	if !ok || (field.Kind() == reflect.Ptr && field.IsNil()) {
+		return ""
+	}
+	return formatKey(unrefValue(field).Interface())
+}
+
+func fieldByColumn(dialect Dialect, owner reflect.Value, column string) (reflect.Value, error) {
+	index, err := columnIndex(dialect, owner.Type(), column)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	field, ok := fieldValue(owner, index, true)
+	if !ok {
+		return reflect.Value{}, errorf("not found column %s in %s", column, owner.Type().Name())
+	}
+	return field, nil
+}
+
+func columnIndex(dialect Dialect, rt reflect.Type, column string) ([]int, error) {
+	fields, err := parseFields(dialect, rt)
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range fields {
+		if f.column.Name == column {
+			return f.index, nil
+		}
+	}
+	return nil, errorf("not found column %s in %s", column, rt.Name())
+}
+
+func formatKey(v interface{}) string {
+	if b, ok := v.([]byte); ok {
+		return string(b)
+	}
+	return fmt.Sprint(v)
+}
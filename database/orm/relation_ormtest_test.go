@@ -0,0 +1,92 @@
+package orm_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/medivhyang/golib/database/orm"
+	"github.com/medivhyang/golib/database/orm/ormtest"
+)
+
+type preloadUser struct {
+	ID     int            `orm:"id"`
+	Name   string         `orm:"name"`
+	Orders []preloadOrder `orm:",has_many,foreign_key=user_id"`
+	Tags   []*preloadTag  `orm:",many_to_many=user_tags,join_foreign_key=user_id,join_references=tag_id"`
+}
+
+func (preloadUser) Table() string { return "user" }
+
+type preloadOrder struct {
+	ID     int          `orm:"id"`
+	UserID int          `orm:"user_id"`
+	User   *preloadUser `orm:",belongs_to,foreign_key=user_id"`
+}
+
+func (preloadOrder) Table() string { return "order" }
+
+type preloadTag struct {
+	ID   int    `orm:"id"`
+	Name string `orm:"name"`
+}
+
+func (preloadTag) Table() string { return "tag" }
+
+// maxParamsDialect limits the params of a statement, so that preload splits its in lists.
+type maxParamsDialect struct {
+	ormtest.Dialect
+}
+
+func (maxParamsDialect) MaxParams() int {
+	return 2
+}
+
+func TestPreload(t *testing.T) {
+	ctx := context.Background()
+	db := ormtest.New(maxParamsDialect{})
+	db.ExpectQuery(`select "id","name" from "user"`).
+		WillReturnRows(ormtest.NewRows("id", "name").AddRow(1, "a").AddRow(2, "b").AddRow(3, "c"))
+	db.ExpectQuery(`select "id","user_id" from "order" where "user_id" in (?, ?)`).WithArgs(1, 2).
+		WillReturnRows(ormtest.NewRows("id", "user_id").AddRow(10, 1).AddRow(11, 1).AddRow(20, 2))
+	db.ExpectQuery(`select "id","user_id" from "order" where "user_id" in (?)`).WithArgs(3).
+		WillReturnRows(ormtest.NewRows("id", "user_id"))
+	db.ExpectQuery(`select "id","name" from "user" where "id" in (?, ?)`).WithArgs(1, 2).
+		WillReturnRows(ormtest.NewRows("id", "name").AddRow(1, "a").AddRow(2, "b"))
+	db.ExpectQuery(`select "user_id","tag_id" from "user_tags" where "user_id" in (?, ?)`).WithArgs(1, 2).
+		WillReturnRows(ormtest.NewRows("user_id", "tag_id").AddRow(1, 100).AddRow(2, 100).AddRow(2, 200))
+	db.ExpectQuery(`select "user_id","tag_id" from "user_tags" where "user_id" in (?)`).WithArgs(3).
+		WillReturnRows(ormtest.NewRows("user_id", "tag_id").AddRow(3, 300))
+	db.ExpectQuery(`select "id","name" from "tag" where "id" in (?, ?)`).WithArgs(int64(100), int64(200)).
+		WillReturnRows(ormtest.NewRows("id", "name").AddRow(100, "go").AddRow(200, "sql"))
+	db.ExpectQuery(`select "id","name" from "tag" where "id" in (?)`).WithArgs(int64(300)).
+		WillReturnRows(ormtest.NewRows("id", "name").AddRow(300, "orm"))
+
+	var users []preloadUser
+	if err := orm.New(db.Dialect()).SelectModel(preloadUser{}).Preload("Orders.User", "Tags").Query(ctx, db, &users); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, u := range users {
+		s := fmt.Sprintf("%d %s orders=[", u.ID, u.Name)
+		for _, o := range u.Orders {
+			s += fmt.Sprintf(" %d(user %s)", o.ID, o.User.Name)
+		}
+		s += " ] tags=["
+		for _, tag := range u.Tags {
+			s += " " + tag.Name
+		}
+		got = append(got, s+" ]")
+	}
+	want := []string{
+		"1 a orders=[ 10(user a) 11(user a) ] tags=[ go ]",
+		"2 b orders=[ 20(user b) ] tags=[ go sql ]",
+		"3 c orders=[ ] tags=[ orm ]",
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("got:\n%q\nwant:\n%q", got, want)
+	}
+}
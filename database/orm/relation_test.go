@@ -0,0 +1,36 @@
+package orm
+
+import "fmt"
+
+func ExampleParseRelations() {
+	type Tag struct {
+		ID int `orm:"id"`
+	}
+	type Order struct {
+		ID     int `orm:"id"`
+		UserID int `orm:"user_id"`
+	}
+	type User struct {
+		ID     int     `orm:"id"`
+		Orders []Order `orm:",has_many"`
+		Tags   []Tag   `orm:",many_to_many=user_tags"`
+	}
+	rr, err := ParseRelations(User{})
+	if err != nil {
+		panic(err)
+	}
+	for _, r := range rr {
+		switch r.Kind {
+		case RelationHasMany:
+			fmt.Println(r.Field, r.Kind, r.ForeignKey, r.References)
+		case RelationManyToMany:
+			fmt.Println(r.Field, r.Kind, r.JoinTable, r.JoinForeignKey, r.JoinReferences)
+		}
+	}
+	t, _ := ParseTable(&TestDialect{}, User{})
+	fmt.Println(t.ColumnNames())
+	// output:
+	// Orders has_many user_id id
+	// Tags many_to_many user_tags user_id tag_id
+	// [id] <nil>
+}
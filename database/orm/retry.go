@@ -0,0 +1,129 @@
+package orm
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"reflect"
+	"time"
+)
+
+type RetryableDialect interface {
+	Dialect
+	IsRetryable(err error) bool
+}
+
+// IsRetryable reports whether err is retryable by the dialect,
+// or has the SQLSTATE of serialization failure (40001) or deadlock (40P01).
+func IsRetryable(dialect Dialect, err error) bool {
+	if err == nil {
+		return false
+	}
+	if d, ok := dialect.(RetryableDialect); ok && d.IsRetryable(err) {
+		return true
+	}
+	switch SQLState(err) {
+	case "40001", "40P01":
+		return true
+	}
+	return false
+}
+
+// SQLState returns the SQLSTATE code of err, from a SQLState() string method
+// like pgconn.PgError and pq.Error, or a SQLState [5]byte field like mysql.MySQLError.
+func SQLState(err error) string {
+	for ; err != nil; err = errors.Unwrap(err) {
+		if e, ok := err.(interface{ SQLState() string }); ok {
+			return e.SQLState()
+		}
+		rv := reflect.ValueOf(err)
+		for rv.Kind() == reflect.Ptr && !rv.IsNil() {
+			rv = rv.Elem()
+		}
+		if rv.Kind() != reflect.Struct {
+			continue
+		}
+		f := rv.FieldByName("SQLState")
+		switch {
+		case f.Kind() == reflect.String:
+			return f.String()
+		case f.Kind() == reflect.Array && f.Type().Elem().Kind() == reflect.Uint8:
+			b := make([]byte, f.Len())
+			for i := range b {
+				b[i] = byte(f.Index(i).Uint())
+			}
+			return string(b)
+		}
+	}
+	return ""
+}
+
+type RetryOptions struct {
+	// MaxAttempts defaults to 3.
+	MaxAttempts int
+	// Backoff is the delay before the first retry, doubled on every attempt, defaults to 10ms.
+	Backoff time.Duration
+	// Retryable defaults to the db dialect's IsRetryable.
+	Retryable func(err error) bool
+	TxOptions *sql.TxOptions
+}
+
+// TxRetry runs fn in a transaction and retries the whole transaction on retryable errors.
+// If db is already in a transaction, fn runs once in a savepoint without retry,
+// since a deadlock may have rolled back the outer transaction, which its owner has to retry.
+func TxRetry(ctx context.Context, db DBTX, fn func(ctx context.Context, tx DBTX) error, options ...RetryOptions) error {
+	var opts RetryOptions
+	if len(options) > 0 {
+		opts = options[0]
+	}
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = 3
+	}
+	if opts.Backoff <= 0 {
+		opts.Backoff = 10 * time.Millisecond
+	}
+	if opts.Retryable == nil {
+		opts.Retryable = func(err error) bool {
+			return IsRetryable(DialectOf(db), err)
+		}
+	}
+	var txOptions []*sql.TxOptions
+	if opts.TxOptions != nil {
+		txOptions = append(txOptions, opts.TxOptions)
+	}
+	if inTx(db) {
+		return db.Tx(ctx, fn, txOptions...)
+	}
+	backoff := opts.Backoff
+	for attempt := 1; ; attempt++ {
+		err := db.Tx(ctx, fn, txOptions...)
+		if err == nil || attempt >= opts.MaxAttempts || !opts.Retryable(err) {
+			return err
+		}
+		debugf("tx: retry attempt %d after %s: %v", attempt, backoff, err)
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+		backoff *= 2
+	}
+}
+
+func inTx(dbtx DBTX) bool {
+	for {
+		switch v := dbtx.(type) {
+		case *db:
+			_, ok := v.raw.(*sql.Tx)
+			return ok
+		case interface{ InTx() bool }:
+			return v.InTx()
+		case unwrapper:
+			dbtx = v.Unwrap()
+		default:
+			return false
+		}
+	}
+}
@@ -8,6 +8,8 @@ import (
 type Rows struct {
 	dialect Dialect
 	raw     *sql.Rows
+	// onClose is called once when the rows are closed, with the error of the iteration if any.
+	onClose func(err error)
 }
 
 func NewRows(dialect Dialect, rows *sql.Rows) *Rows {
@@ -15,6 +17,7 @@ func NewRows(dialect Dialect, rows *sql.Rows) *Rows {
 }
 
 func (r *Rows) Scan(callback func(scan func(...interface{}) error, abort func()) error) error {
+	defer r.Close()
 	if callback == nil {
 		return nil
 	}
@@ -28,7 +31,16 @@ func (r *Rows) Scan(callback func(scan func(...interface{}) error, abort func())
 			break
 		}
 	}
-	return r.raw.Close()
+	return r.Close()
+}
+
+func (r *Rows) Close() error {
+	err := r.raw.Close()
+	if f := r.onClose; f != nil {
+		r.onClose = nil
+		f(r.raw.Err())
+	}
+	return err
 }
 
 func (r *Rows) Bind(dst interface{}) error {
@@ -48,30 +60,44 @@ func (r *Rows) Bind(dst interface{}) error {
 		}
 		value.Set(reflect.ValueOf(m))
 	default:
-		switch value.Kind() {
-		case reflect.Struct:
-			if err := r.Struct(value); err != nil {
+		_, isType := LookupType(value.Type())
+		switch {
+		case isType:
+			if err := r.Scalar(value.Addr().Interface()); err != nil {
+				return err
+			}
+		case isStructValue(value):
+			if err := r.Struct(value.Addr().Interface()); err != nil {
 				return err
 			}
-		case reflect.Slice:
-			switch value.Type().Elem().Kind() {
-			case reflect.Map:
+		case value.Kind() == reflect.Slice:
+			elemType := value.Type().Elem()
+			if elemType.Kind() == reflect.Ptr && elemType.Elem().Kind() == reflect.Struct {
+				elemType = elemType.Elem()
+			}
+			_, isElemType := LookupType(elemType)
+			switch {
+			case isElemType:
+				if err := r.ScalarSlice(value.Addr().Interface()); err != nil {
+					return err
+				}
+			case elemType.Kind() == reflect.Map:
 				ss, err := r.MapSlice()
 				if err != nil {
 					return err
 				}
 				value.Set(reflect.ValueOf(ss))
-			case reflect.Struct:
-				if err := r.StructSlice(value); err != nil {
+			case isStructValue(reflect.New(elemType).Elem()):
+				if err := r.StructSlice(value.Addr().Interface()); err != nil {
 					return err
 				}
 			default:
-				if err := r.ScalarSlice(value); err != nil {
+				if err := r.ScalarSlice(value.Addr().Interface()); err != nil {
 					return err
 				}
 			}
 		default:
-			if err := r.Scalar(value); err != nil {
+			if err := r.Scalar(value.Addr().Interface()); err != nil {
 				return err
 			}
 		}
@@ -80,16 +106,18 @@ func (r *Rows) Bind(dst interface{}) error {
 }
 
 func (r *Rows) Scalar(value interface{}) error {
+	defer r.Close()
 	if !r.raw.Next() {
 		return sql.ErrNoRows
 	}
-	if err := r.raw.Scan(value); err != nil {
+	if err := r.raw.Scan(scanTarget(value)); err != nil {
 		return err
 	}
-	return r.raw.Close()
+	return r.Close()
 }
 
 func (r *Rows) ScalarSlice(slice interface{}) error {
+	defer r.Close()
 	reflectValue := reflect.ValueOf(slice)
 	if reflectValue.Kind() != reflect.Ptr {
 		return ErrRequirePointerType
@@ -103,16 +131,17 @@ func (r *Rows) ScalarSlice(slice interface{}) error {
 
 	for r.raw.Next() {
 		item := reflect.New(elemType)
-		if err := r.raw.Scan(item.Interface()); err != nil {
+		if err := r.raw.Scan(scanTarget(item.Interface())); err != nil {
 			return err
 		}
 		reflectValue.Set(reflect.Append(reflectValue, item.Elem()))
 	}
 
-	return r.raw.Close()
+	return r.Close()
 }
 
 func (r *Rows) Map() (map[string]interface{}, error) {
+	defer r.Close()
 	columns, err := r.raw.Columns()
 	if err != nil {
 		return nil, err
@@ -135,7 +164,7 @@ func (r *Rows) Map() (map[string]interface{}, error) {
 	for i, v := range values {
 		item[columns[i]] = *v.(*interface{})
 	}
-	if err := r.raw.Close(); err != nil {
+	if err := r.Close(); err != nil {
 		return nil, err
 	}
 
@@ -143,6 +172,7 @@ func (r *Rows) Map() (map[string]interface{}, error) {
 }
 
 func (r *Rows) MapSlice() ([]map[string]interface{}, error) {
+	defer r.Close()
 	columns, err := r.raw.Columns()
 	if err != nil {
 		return nil, err
@@ -166,7 +196,7 @@ func (r *Rows) MapSlice() ([]map[string]interface{}, error) {
 		items = append(items, item)
 	}
 
-	if err := r.raw.Close(); err != nil {
+	if err := r.Close(); err != nil {
 		return nil, err
 	}
 
@@ -174,6 +204,7 @@ func (r *Rows) MapSlice() ([]map[string]interface{}, error) {
 }
 
 func (r *Rows) Struct(i interface{}) error {
+	defer r.Close()
 	rv := reflect.ValueOf(i)
 	if rv.Kind() != reflect.Ptr {
 		return ErrRequirePointerType
@@ -182,36 +213,24 @@ func (r *Rows) Struct(i interface{}) error {
 	if err != nil {
 		return err
 	}
-	columnValuePairs, err := ParseColumnValuePairs(r.dialect, i)
-	if err != nil {
-		return err
-	}
-	columnValuePairsMap := make(map[string]interface{}, len(columnValuePairs))
-	for _, pair := range columnValuePairs {
-		columnValuePairsMap[pair.Column] = pair.Value
-	}
-	vv := make([]interface{}, 0, len(columns))
-	for _, c := range columns {
-		if f, ok := columnValuePairsMap[c]; ok {
-			vv = append(vv, f)
-		} else {
-			var tmp interface{}
-			vv = append(vv, &tmp)
-		}
-	}
 	if !r.raw.Next() {
 		return sql.ErrNoRows
 	}
+	vv, err := r.structValues(columns, i)
+	if err != nil {
+		return err
+	}
 	if err := r.raw.Scan(vv...); err != nil {
 		return err
 	}
-	if err := r.raw.Close(); err != nil {
+	if err := r.Close(); err != nil {
 		return err
 	}
 	return nil
 }
 
 func (r *Rows) StructSlice(i interface{}) error {
+	defer r.Close()
 	reflectValue := reflect.ValueOf(i)
 	if reflectValue.Kind() != reflect.Ptr {
 		return ErrRequirePointerType
@@ -224,32 +243,45 @@ func (r *Rows) StructSlice(i interface{}) error {
 	if err != nil {
 		return err
 	}
-	values := make([]interface{}, 0, len(columns))
+	elemType := unrefReflectValue.Type().Elem()
+	isPtr := elemType.Kind() == reflect.Ptr
+	if isPtr {
+		elemType = elemType.Elem()
+	}
 	for r.raw.Next() {
-		item := reflect.New(unrefReflectValue.Type().Elem())
-		columnValuePairs, err := ParseColumnValuePairs(r.dialect, item)
+		item := reflect.New(elemType)
+		values, err := r.structValues(columns, item.Interface())
 		if err != nil {
 			return err
 		}
-		columnValuePairsMap := make(map[string]interface{}, len(columnValuePairs))
-		for _, pair := range columnValuePairs {
-			columnValuePairsMap[pair.Column] = pair.Value
-		}
-		for _, c := range columns {
-			if v, ok := columnValuePairsMap[c]; ok {
-				values = append(values, v)
-			} else {
-				var temp interface{}
-				values = append(values, &temp)
-			}
-		}
 		if err := r.raw.Scan(values...); err != nil {
 			return err
 		}
-		reflectValue.Set(reflect.Append(reflectValue, item))
+		if isPtr {
+			unrefReflectValue.Set(reflect.Append(unrefReflectValue, item))
+		} else {
+			unrefReflectValue.Set(reflect.Append(unrefReflectValue, item.Elem()))
+		}
 	}
-	if err := r.raw.Close(); err != nil {
+	if err := r.Close(); err != nil {
 		return err
 	}
 	return nil
 }
+
+func (r *Rows) structValues(columns []string, i interface{}) ([]interface{}, error) {
+	pointers, err := ParseColumnPointers(r.dialect, i)
+	if err != nil {
+		return nil, err
+	}
+	values := make([]interface{}, 0, len(columns))
+	for _, c := range columns {
+		if p, ok := pointers[c]; ok {
+			values = append(values, p)
+		} else {
+			var temp interface{}
+			values = append(values, &temp)
+		}
+	}
+	return values, nil
+}
@@ -0,0 +1,244 @@
+package orm
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// Scope adds conditions to the queries of a table, registered by RegisterScope.
+type Scope struct {
+	// Where returns the condition added to select, update and delete,
+	// table is the name or alias of the table to qualify columns with.
+	Where func(ctx context.Context, table string) (Expr, error)
+	// Insert returns the column values set on insert when they are missing or zero.
+	Insert func(ctx context.Context) (map[string]interface{}, error)
+}
+
+type namedScope struct {
+	name  string
+	scope Scope
+}
+
+type tableScope struct {
+	table   string
+	alias   string
+	skipAll bool
+	skips   []string
+}
+
+var (
+	scopesMu sync.RWMutex
+	scopes   = map[string][]namedScope{}
+)
+
+// RegisterScope registers a scope named name for the table of model, model can be a table name.
+func RegisterScope(name string, model interface{}, scope Scope) {
+	table := scopeTableName(model)
+	scopesMu.Lock()
+	defer scopesMu.Unlock()
+	for i, s := range scopes[table] {
+		if s.name == name {
+			scopes[table][i].scope = scope
+			return
+		}
+	}
+	scopes[table] = append(scopes[table], namedScope{name: name, scope: scope})
+}
+
+func UnregisterScope(name string, model interface{}) {
+	table := scopeTableName(model)
+	scopesMu.Lock()
+	defer scopesMu.Unlock()
+	ss := scopes[table]
+	for i, s := range ss {
+		if s.name == name {
+			scopes[table] = append(ss[:i:i], ss[i+1:]...)
+			return
+		}
+	}
+}
+
+func scopeTableName(model interface{}) string {
+	if s, ok := model.(string); ok {
+		return s
+	}
+	return ParseTableName(model)
+}
+
+// qualifier returns the alias or the name of the table, which qualifies the columns of scope conditions.
+func (s tableScope) qualifier() string {
+	if s.alias != "" {
+		return s.alias
+	}
+	return s.table
+}
+
+func newTableScope(table interface{}) tableScope {
+	s, ok := table.(string)
+	if !ok {
+		return tableScope{}
+	}
+	name, alias := splitTableAlias(s)
+	return tableScope{table: name, alias: alias}
+}
+
+// WithContext sets the context that Build passes to scopes, Query and Exec use their own context,
+// subquery builders used as values need their own WithContext.
+func (b *Builder) WithContext(ctx context.Context) *Builder {
+	if b.err != nil {
+		return b
+	}
+	b.ctx = ctx
+	return b
+}
+
+func (b *Builder) context() context.Context {
+	if b.ctx == nil {
+		return context.Background()
+	}
+	return b.ctx
+}
+
+// WithoutScopes skips the scopes named names, or all scopes if no name is given, e.g. for admin jobs.
+func (b *Builder) WithoutScopes(names ...string) *Builder {
+	if b.err != nil {
+		return b
+	}
+	if len(names) == 0 {
+		b.scope.skipAll = true
+	}
+	b.scope.skips = append(b.scope.skips, names...)
+	return b
+}
+
+func (b *Builder) activeScopes() []namedScope {
+	if b.scope.table == "" || b.scope.skipAll {
+		return nil
+	}
+	scopesMu.RLock()
+	defer scopesMu.RUnlock()
+	var result []namedScope
+	for _, s := range scopes[b.scope.table] {
+		if !containStrings(b.scope.skips, s.name) {
+			result = append(result, s)
+		}
+	}
+	return result
+}
+
+func (b *Builder) appendScopes(ctx context.Context, where *Condition) error {
+	switch b.action {
+	case actionSelect, actionUpdate, actionDelete:
+	default:
+		return nil
+	}
+	table := b.scope.qualifier()
+	for _, s := range b.activeScopes() {
+		if s.scope.Where == nil {
+			continue
+		}
+		e, err := s.scope.Where(ctx, table)
+		if err != nil {
+			return err
+		}
+		if !e.IsEmpty() {
+			where.AppendExpr(e)
+		}
+	}
+	return where.Err()
+}
+
+func (b *Builder) scopedColumns(ctx context.Context, columns Templates) (Templates, error) {
+	for _, s := range b.activeScopes() {
+		if s.scope.Insert == nil {
+			continue
+		}
+		values, err := s.scope.Insert(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range sortedKeys(values) {
+			value := values[name]
+			i := indexTemplate(columns, name)
+			switch {
+			case i < 0:
+				columns = append(columns, NewTemplate(name, value))
+			case len(columns[i].Values) == 1 && isZeroValue(columns[i].Values[0]):
+				columns[i] = NewTemplate(name, value)
+			default:
+				continue
+			}
+			if b.model != nil {
+				b.setModelColumn(name, value)
+			}
+		}
+	}
+	return columns, nil
+}
+
+func (b *Builder) setModelColumn(column string, value interface{}) {
+	field, err := fieldByColumn(b.dialect, unrefValue(reflect.ValueOf(b.model)), column)
+	if err != nil || !field.CanSet() {
+		return
+	}
+	v := reflect.ValueOf(value)
+	if v.IsValid() && v.Type().ConvertibleTo(field.Type()) {
+		field.Set(v.Convert(field.Type()))
+	}
+}
+
+func indexTemplate(tt Templates, format string) int {
+	for i, t := range tt {
+		if t.Format == format {
+			return i
+		}
+	}
+	return -1
+}
+
+func isZeroValue(v interface{}) bool {
+	return v == nil || reflect.ValueOf(v).IsZero()
+}
+
+func splitTableAlias(s string) (string, string) {
+	name, alias, _ := strings.Cut(strings.TrimSpace(s), " ")
+	return name, strings.TrimSpace(alias)
+}
+
+type tenantKey struct{}
+
+var ErrMissingTenant = errorf("missing tenant")
+
+func WithTenant(ctx context.Context, tenant interface{}) context.Context {
+	return context.WithValue(ctx, tenantKey{}, tenant)
+}
+
+func TenantFromContext(ctx context.Context) (interface{}, bool) {
+	tenant := ctx.Value(tenantKey{})
+	return tenant, tenant != nil
+}
+
+// TenantScope filters by and populates column with the tenant of the context,
+// returning ErrMissingTenant if the context has no tenant, e.g.
+//
+//	orm.RegisterScope("tenant", Order{}, orm.TenantScope("tenant_id"))
+func TenantScope(column string) Scope {
+	return Scope{
+		Where: func(ctx context.Context, table string) (Expr, error) {
+			tenant, ok := TenantFromContext(ctx)
+			if !ok {
+				return Expr{}, ErrMissingTenant
+			}
+			return Col(table + "." + column).Eq(tenant), nil
+		},
+		Insert: func(ctx context.Context) (map[string]interface{}, error) {
+			tenant, ok := TenantFromContext(ctx)
+			if !ok {
+				return nil, ErrMissingTenant
+			}
+			return map[string]interface{}{column: tenant}, nil
+		},
+	}
+}
@@ -0,0 +1,22 @@
+package orm
+
+import (
+	"context"
+	"fmt"
+)
+
+func ExampleTenantScope() {
+	RegisterScope("tenant", "invoice", TenantScope("tenant_id"))
+	defer UnregisterScope("tenant", "invoice")
+
+	ctx := WithTenant(context.Background(), 7)
+	fmt.Println(New(&TestDialect{}).Select("invoice", "id").Where("paid = ?", true).WithContext(ctx).Build())
+	fmt.Println(New(&TestDialect{}).Insert("invoice", map[string]interface{}{"id": 1}).WithContext(ctx).Build())
+	fmt.Println(New(&TestDialect{}).Delete("invoice").WithoutScopes("tenant").Build())
+	fmt.Println(New(&TestDialect{}).Delete("invoice").Build().Err)
+	// output:
+	// "select 'id' from 'invoice' where paid = ? and ('invoice'.'tenant_id' = ?)": []interface {}{true, 7}
+	// "insert into 'invoice'(id, tenant_id) values(?,?)": []interface {}{1, 7}
+	// "delete from 'invoice'"
+	// orm: missing tenant
+}
@@ -0,0 +1,33 @@
+package orm
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// sqliteTestDialect is TestDialect quoting identifiers the way sqlite expects.
+type sqliteTestDialect struct {
+	TestDialect
+}
+
+func (d *sqliteTestDialect) Quote(s string) string {
+	return `"` + s + `"`
+}
+
+// openTestDB opens a sqlite file in a temp dir, which is shared by all connections of the pool.
+func openTestDB(t testing.TB, statements ...string) (*sql.DB, DBTX) {
+	raw, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "test.db")+"?_busy_timeout=5000")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { raw.Close() })
+	for _, s := range statements {
+		if _, err := raw.Exec(s); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return raw, NewDB(&sqliteTestDialect{}, raw)
+}
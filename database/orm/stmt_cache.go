@@ -0,0 +1,272 @@
+package orm
+
+import (
+	"container/list"
+	"context"
+	"database/sql"
+	"strings"
+	"sync"
+)
+
+const DefaultStmtCacheSize = 128
+
+type stmtCache struct {
+	mu    sync.Mutex
+	size  int
+	raw   *sql.DB
+	items map[string]*list.Element
+	lru   *list.List
+}
+
+// stmtCacheItem is reference counted, a statement removed from the cache is closed on its last release.
+type stmtCacheItem struct {
+	format  string
+	stmt    *sql.Stmt
+	refs    int
+	removed bool
+}
+
+// WithStmtCache returns a copy of db that prepares statements once and reuses them by template format,
+// keeping at most size statements, db must be opened by OpenDB or NewDB with a *sql.DB.
+func WithStmtCache(dbtx DBTX, size int) (DBTX, error) {
+	d, ok := dbtx.(*db)
+	if !ok {
+		return nil, errorf("stmt cache: unsupported db type %T", dbtx)
+	}
+	raw, ok := d.raw.(*sql.DB)
+	if !ok {
+		return nil, errorf("stmt cache: require *sql.DB")
+	}
+	if size <= 0 {
+		size = DefaultStmtCacheSize
+	}
+	c := *d
+	c.stmts = &stmtCache{size: size, raw: raw, items: map[string]*list.Element{}, lru: list.New()}
+	return &c, nil
+}
+
+// get returns the cached statement of format, the caller must release it after use.
+func (c *stmtCache) get(ctx context.Context, format string) (*stmtCacheItem, error) {
+	c.mu.Lock()
+	if e, ok := c.items[format]; ok {
+		c.lru.MoveToFront(e)
+		item := e.Value.(*stmtCacheItem)
+		item.refs++
+		c.mu.Unlock()
+		return item, nil
+	}
+	c.mu.Unlock()
+
+	debugf("prepare: %q", format)
+	stmt, err := c.raw.PrepareContext(ctx, format)
+	if err != nil {
+		return nil, err
+	}
+
+	var evicted []*sql.Stmt
+	c.mu.Lock()
+	if e, ok := c.items[format]; ok {
+		c.lru.MoveToFront(e)
+		item := e.Value.(*stmtCacheItem)
+		item.refs++
+		c.mu.Unlock()
+		stmt.Close()
+		return item, nil
+	}
+	item := &stmtCacheItem{format: format, stmt: stmt, refs: 1}
+	c.items[format] = c.lru.PushFront(item)
+	for c.lru.Len() > c.size {
+		if s := c.removeLocked(c.lru.Back()); s != nil {
+			evicted = append(evicted, s)
+		}
+	}
+	c.mu.Unlock()
+
+	for _, s := range evicted {
+		s.Close()
+	}
+	return item, nil
+}
+
+func (c *stmtCache) release(item *stmtCacheItem) {
+	c.mu.Lock()
+	item.refs--
+	closing := item.removed && item.refs == 0
+	c.mu.Unlock()
+	if closing {
+		item.stmt.Close()
+	}
+}
+
+// removeLocked removes e from the cache, and returns its statement if it should be closed now.
+func (c *stmtCache) removeLocked(e *list.Element) *sql.Stmt {
+	item := c.lru.Remove(e).(*stmtCacheItem)
+	delete(c.items, item.format)
+	item.removed = true
+	if item.refs > 0 {
+		return nil
+	}
+	return item.stmt
+}
+
+func (c *stmtCache) remove(format string) {
+	var stmt *sql.Stmt
+	c.mu.Lock()
+	if e, ok := c.items[format]; ok {
+		stmt = c.removeLocked(e)
+	}
+	c.mu.Unlock()
+	if stmt != nil {
+		stmt.Close()
+	}
+}
+
+func (c *stmtCache) clear() {
+	var closing []*sql.Stmt
+	c.mu.Lock()
+	for c.lru.Len() > 0 {
+		if s := c.removeLocked(c.lru.Back()); s != nil {
+			closing = append(closing, s)
+		}
+	}
+	c.mu.Unlock()
+	for _, s := range closing {
+		s.Close()
+	}
+}
+
+// cacheable reports whether format is a single statement that doesn't change the schema.
+func cacheable(format string) (ok bool, ddl bool) {
+	s := strings.TrimSpace(format)
+	s = strings.TrimSpace(strings.TrimSuffix(s, ";"))
+	word, _, _ := strings.Cut(strings.ToLower(s), " ")
+	switch word {
+	case "create", "alter", "drop", "truncate", "rename":
+		return false, true
+	case "begin", "commit", "rollback", "savepoint", "release":
+		return false, false
+	}
+	return !strings.Contains(s, ";"), false
+}
+
+func isSchemaChangedError(err error) bool {
+	if err == nil {
+		return false
+	}
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "schema has changed") ||
+		strings.Contains(msg, "needs to be re-prepared") ||
+		strings.Contains(msg, "cached plan must not change result type")
+}
+
+func (db *db) stmt(ctx context.Context, format string) (*sql.Stmt, func(), bool) {
+	if db.stmts == nil {
+		return db.txStmt(ctx, format)
+	}
+	ok, ddl := cacheable(format)
+	if ddl {
+		db.stmts.clear()
+	}
+	if !ok {
+		return nil, nil, false
+	}
+	item, err := db.stmts.get(ctx, format)
+	if err != nil {
+		debugf("prepare: %q failed: %v", format, err)
+		return nil, nil, false
+	}
+	release := func() { db.stmts.release(item) }
+	if tx, ok := db.raw.(*sql.Tx); ok {
+		return tx.StmtContext(ctx, item.stmt), release, true
+	}
+	return item.stmt, release, true
+}
+
+func (db *db) queryStmt(ctx context.Context, t Template) (*sql.Rows, error) {
+	stmt, release, ok := db.stmt(ctx, t.Format)
+	if !ok {
+		return db.raw.QueryContext(ctx, t.Format, t.Values...)
+	}
+	rows, err := stmt.QueryContext(ctx, t.Values...)
+	release()
+	if isSchemaChangedError(err) {
+		db.removeStmt(ctx, t.Format)
+		return db.raw.QueryContext(ctx, t.Format, t.Values...)
+	}
+	return rows, err
+}
+
+func (db *db) execStmt(ctx context.Context, t Template) (sql.Result, error) {
+	stmt, release, ok := db.stmt(ctx, t.Format)
+	if !ok {
+		return db.raw.ExecContext(ctx, t.Format, t.Values...)
+	}
+	result, err := stmt.ExecContext(ctx, t.Values...)
+	release()
+	if isSchemaChangedError(err) {
+		db.removeStmt(ctx, t.Format)
+		return db.raw.ExecContext(ctx, t.Format, t.Values...)
+	}
+	return result, err
+}
+
+func (db *db) removeStmt(ctx context.Context, format string) {
+	if db.stmts != nil {
+		db.stmts.remove(format)
+		return
+	}
+	if stmts, ok := ctx.Value(txStmtsKey{}).(*txStmts); ok {
+		stmts.remove(format)
+	}
+}
+
+type txStmtsKey struct{}
+
+// txStmts are statements prepared on a transaction, which closes them on commit or rollback.
+type txStmts struct {
+	mu    sync.Mutex
+	items map[string]*sql.Stmt
+}
+
+// withTxStmts returns a context in which execs and queries of transactions without a statement cache
+// reuse the statements prepared on the transaction.
+func withTxStmts(ctx context.Context) context.Context {
+	return context.WithValue(ctx, txStmtsKey{}, &txStmts{items: map[string]*sql.Stmt{}})
+}
+
+func (db *db) txStmt(ctx context.Context, format string) (*sql.Stmt, func(), bool) {
+	stmts, ok := ctx.Value(txStmtsKey{}).(*txStmts)
+	if !ok {
+		return nil, nil, false
+	}
+	tx, ok := db.raw.(*sql.Tx)
+	if !ok {
+		return nil, nil, false
+	}
+	if ok, _ := cacheable(format); !ok {
+		return nil, nil, false
+	}
+	stmts.mu.Lock()
+	defer stmts.mu.Unlock()
+	if stmt, ok := stmts.items[format]; ok {
+		return stmt, func() {}, true
+	}
+	debugf("prepare: %q", format)
+	stmt, err := tx.PrepareContext(ctx, format)
+	if err != nil {
+		debugf("prepare: %q failed: %v", format, err)
+		return nil, nil, false
+	}
+	stmts.items[format] = stmt
+	return stmt, func() {}, true
+}
+
+func (s *txStmts) remove(format string) {
+	s.mu.Lock()
+	stmt, ok := s.items[format]
+	delete(s.items, format)
+	s.mu.Unlock()
+	if ok {
+		stmt.Close()
+	}
+}
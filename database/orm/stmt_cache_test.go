@@ -0,0 +1,68 @@
+package orm
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+)
+
+func Example_cacheable() {
+	for _, format := range []string{
+		"select * from user where id = ?",
+		"insert into user(id) values(?);",
+		"create table user (id integer)",
+		"savepoint sp_1",
+		"delete from user; delete from role",
+	} {
+		ok, ddl := cacheable(format)
+		fmt.Println(ok, ddl)
+	}
+	// output:
+	// true false
+	// true false
+	// false true
+	// false false
+	// false false
+}
+
+func TestStmtCacheConcurrentEviction(t *testing.T) {
+	_, dbtx := openTestDB(t, "create table user (id integer primary key, name text)", "insert into user(name) values ('Medivh'), ('Jason')")
+	cached, err := WithStmtCache(dbtx, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	var wg sync.WaitGroup
+	errs := make(chan error, 16)
+	for g := 0; g < 16; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 200; i++ {
+				var names []string
+				// distinct formats keep evicting statements other goroutines are using
+				tpl := NewTemplate(fmt.Sprintf("select name from user where id > ? and %d = %d order by id", (g+i)%5, (g+i)%5), 0)
+				if err := cached.Query(ctx, tpl, &names); err != nil {
+					errs <- err
+					return
+				}
+				if len(names) != 2 {
+					errs <- fmt.Errorf("got %v", names)
+					return
+				}
+				if i%10 == 0 {
+					if _, err := cached.Exec(ctx, NewTemplate("create table if not exists role (id integer)")); err != nil {
+						errs <- err
+						return
+					}
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+}
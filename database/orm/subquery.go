@@ -0,0 +1,186 @@
+package orm
+
+import (
+	"context"
+	"strings"
+)
+
+type cte struct {
+	name    string
+	builder *Builder
+}
+
+type union struct {
+	op      string
+	builder *Builder
+}
+
+func (b *Builder) As(alias string) *Builder {
+	if b.err != nil {
+		return b
+	}
+	b.alias = alias
+	return b
+}
+
+func (b *Builder) With(name string, builder *Builder) *Builder {
+	if b.err != nil {
+		return b
+	}
+	b.ctes = append(b.ctes, cte{name: name, builder: builder})
+	return b
+}
+
+func (b *Builder) Union(others ...*Builder) *Builder {
+	return b.union("union", others...)
+}
+
+func (b *Builder) UnionAll(others ...*Builder) *Builder {
+	return b.union("union all", others...)
+}
+
+func (b *Builder) union(op string, others ...*Builder) *Builder {
+	if b.err != nil {
+		return b
+	}
+	for _, o := range others {
+		b.unions = append(b.unions, union{op: op, builder: o})
+	}
+	return b
+}
+
+func (b *Builder) Join(table interface{}, on string, values ...interface{}) *Builder {
+	return b.join("join", table, on, values...)
+}
+
+func (b *Builder) LeftJoin(table interface{}, on string, values ...interface{}) *Builder {
+	return b.join("left join", table, on, values...)
+}
+
+func (b *Builder) RightJoin(table interface{}, on string, values ...interface{}) *Builder {
+	return b.join("right join", table, on, values...)
+}
+
+func (b *Builder) join(kind string, table interface{}, on string, values ...interface{}) *Builder {
+	if b.err != nil {
+		return b
+	}
+	t, err := tableTemplate(b.dialect, table)
+	if err != nil {
+		b.err = err
+		return b
+	}
+	cond, err := expandTemplate(on, values)
+	if err != nil {
+		b.err = err
+		return b
+	}
+	b.joins = append(b.joins, NewTemplate(kind+" ").Merge(t).Appendf(" on ").Merge(cond))
+	return b
+}
+
+func (b *Builder) buildWith(ctx context.Context) (Template, error) {
+	tt := make(Templates, 0, len(b.ctes))
+	for _, c := range b.ctes {
+		t := c.builder.build(ctx)
+		if t.Err != nil {
+			return Template{}, t.Err
+		}
+		tt = append(tt, NewTemplate(b.dialect.Quote(c.name)+" as ").Merge(t.Template.Bracket()))
+	}
+	return tt.Join(", ", "with ", " "), nil
+}
+
+// tableTemplate renders a table name, a "name alias" string, a Template or a subquery builder with its alias.
+func tableTemplate(dialect Dialect, table interface{}) (Template, error) {
+	switch v := table.(type) {
+	case string:
+		name, alias := splitTableAlias(v)
+		if alias == "" {
+			return NewTemplate(dialect.Quote(name)), nil
+		}
+		return NewTemplate(dialect.Quote(name) + " " + alias), nil
+	case Template:
+		return v, nil
+	case *Builder:
+		t := v.Build()
+		if t.Err != nil {
+			return Template{}, t.Err
+		}
+		sub := t.Template.Bracket()
+		if v.alias != "" {
+			sub = sub.Appendf(" " + dialect.Quote(v.alias))
+		}
+		return sub, nil
+	default:
+		return Template{}, errorf("invalid table type %T", table)
+	}
+}
+
+// expandTemplate replaces the placeholders of subquery builder values with the subqueries.
+func expandTemplate(format string, values []interface{}) (Template, error) {
+	hasBuilder := false
+	for _, v := range values {
+		if _, ok := v.(*Builder); ok {
+			hasBuilder = true
+			break
+		}
+	}
+	if !hasBuilder {
+		return NewTemplate(format, values...), nil
+	}
+	t := Template{}
+	index := 0
+	for {
+		i := placeholderIndex(format)
+		if i < 0 || index >= len(values) {
+			break
+		}
+		t = t.Appendf(format[:i])
+		format = format[i+1:]
+		if sub, ok := values[index].(*Builder); ok {
+			st := sub.Build()
+			if st.Err != nil {
+				return Template{}, st.Err
+			}
+			t = t.Merge(st.Template.Bracket())
+		} else {
+			t = t.Appendf("?", values[index])
+		}
+		index++
+	}
+	return t.Appendf(format, values[index:]...), nil
+}
+
+// placeholderIndex returns the index of the first placeholder of format outside quoted literals and identifiers, or -1.
+func placeholderIndex(format string) int {
+	var quote byte
+	for i := 0; i < len(format); i++ {
+		c := format[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"' || c == '`':
+			quote = c
+		case c == '?':
+			return i
+		}
+	}
+	return -1
+}
+
+func columnValue(dialect Dialect, column Template) (Template, error) {
+	if len(column.Values) != 1 {
+		return NewTemplate(strings.Join(repeatString("?", len(column.Values)), ","), column.Values...), nil
+	}
+	return exprValue(dialect, column.Values[0])
+}
+
+func quoteSelectColumn(dialect Dialect, column string) string {
+	if strings.ContainsAny(column, "() '\"`") {
+		return column
+	}
+	return quoteColumn(dialect, column)
+}
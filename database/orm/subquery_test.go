@@ -0,0 +1,32 @@
+package orm
+
+import "fmt"
+
+func ExampleBuilder_With() {
+	dialect := &TestDialect{}
+	paid := New(dialect).Select("order", "user_id", "sum(amount) as total").Where("status = ?", "paid").GroupBy("user_id")
+	t := New(dialect).
+		With("paid", paid).
+		Select("user u", "u.id", "p.total").
+		LeftJoin("paid p", "p.user_id = u.id").
+		Where("u.id in ?", New(dialect).Select("vip", "user_id").Where("level > ?", 2)).
+		UnionAll(New(dialect).Select("guest", "id", "0")).
+		Build()
+	fmt.Println(t)
+
+	sub := New(dialect).Select("user", "id").Where("age > ?", 18).As("adult")
+	fmt.Println(New(dialect).Select(sub, "id").Join("profile p", "p.user_id = adult.id and p.public = ?", true).Build())
+	// output:
+	// "with 'paid' as (select 'user_id',sum(amount) as total from 'order' where status = ? group by user_id) select 'u'.'id','p'.'total' from 'user' u left join 'paid' p on p.user_id = u.id where u.id in (select 'user_id' from 'vip' where level > ?) union all select 'id','0' from 'guest'": []interface {}{"paid", 2}
+	// "select 'id' from (select 'id' from 'user' where age > ?) 'adult' join 'profile' p on p.user_id = adult.id and p.public = ?": []interface {}{18, true}
+}
+
+func ExampleBuilder_Where_subqueryLiteral() {
+	dialect := &TestDialect{}
+	vip := New(dialect).Select("vip", "user_id").Where("level > ?", 2)
+	fmt.Println(New(dialect).Select("user", "id").Where("note <> 'why?' and \"a?b\" = ? and id in ?", 1, vip).Build())
+	fmt.Println(New(dialect).Select("user", "id").Where("note = 'it''s ?' and id in ?", vip).Build())
+	// output:
+	// "select 'id' from 'user' where note <> 'why?' and \"a?b\" = ? and id in (select 'user_id' from 'vip' where level > ?)": []interface {}{1, 2}
+	// "select 'id' from 'user' where note = 'it''s ?' and id in (select 'user_id' from 'vip' where level > ?)": []interface {}{2}
+}
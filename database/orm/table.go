@@ -19,9 +19,15 @@ func (t *Table) ColumnNames() ([]string, error) {
 }
 
 type Column struct {
-	Name   string
-	Type   string
-	Suffix string
+	Name    string
+	Type    string
+	Suffix  string
+	Options map[string]string
+}
+
+func (c Column) HasOption(name string) bool {
+	_, ok := c.Options[name]
+	return ok
 }
 
 type ColumnValuePair struct {
@@ -47,20 +53,20 @@ func ParseTable(dialect Dialect, model interface{}) (*Table, error) {
 	if dialect == nil {
 		dialect = GetDefaultDialect()
 	}
-	value := reflect.ValueOf(model)
-	for value.Kind() == reflect.Ptr {
-		value = value.Elem()
+	rt := reflect.TypeOf(model)
+	for rt != nil && rt.Kind() == reflect.Ptr {
+		rt = rt.Elem()
 	}
-	if value.Kind() != reflect.Struct {
+	if rt == nil || rt.Kind() != reflect.Struct {
 		return nil, ErrRequireStructType
 	}
+	fields, err := parseFields(dialect, rt)
+	if err != nil {
+		return nil, err
+	}
 	t := Table{Name: ParseTableName(model), Columns: nil}
-	for i := 0; i < value.NumField(); i++ {
-		c, err := ParseColumn(dialect, value.Type().Field(i))
-		if err != nil {
-			return nil, err
-		}
-		t.Columns = append(t.Columns, c)
+	for _, f := range fields {
+		t.Columns = append(t.Columns, f.column)
 	}
 	return &t, nil
 }
@@ -68,7 +74,11 @@ func ParseTable(dialect Dialect, model interface{}) (*Table, error) {
 func ParseTableName(model interface{}) string {
 	obj, ok := model.(interface{ Table() string })
 	if !ok {
-		return reflect.TypeOf(model).Name()
+		rt := reflect.TypeOf(model)
+		for rt.Kind() == reflect.Ptr {
+			rt = rt.Elem()
+		}
+		return rt.Name()
 	}
 	return obj.Table()
 }
@@ -77,7 +87,7 @@ func ParseColumn(dialect Dialect, sf reflect.StructField) (Column, error) {
 	if dialect == nil {
 		dialect = GetDefaultDialect()
 	}
-	items := strings.Split(sf.Tag.Get(TagKey), " ")
+	items, options := parseTag(sf)
 
 	var finalName string
 	if len(items) >= 1 {
@@ -93,7 +103,7 @@ func ParseColumn(dialect Dialect, sf reflect.StructField) (Column, error) {
 		finalType = items[1]
 	}
 	if finalType == "" {
-		finalType = dialect.MappingType(sf.Type)
+		finalType = mappingFieldType(dialect, sf, options)
 	}
 
 	var suffix string
@@ -102,9 +112,10 @@ func ParseColumn(dialect Dialect, sf reflect.StructField) (Column, error) {
 	}
 
 	return Column{
-		Name:   finalName,
-		Type:   finalType,
-		Suffix: suffix,
+		Name:    finalName,
+		Type:    finalType,
+		Suffix:  suffix,
+		Options: options,
 	}, nil
 }
 
@@ -116,23 +127,190 @@ func ParseColumnValuePairs(dialect Dialect, model interface{}) ([]ColumnValuePai
 	if value.Kind() != reflect.Struct {
 		return nil, ErrRequireStructType
 	}
+	fields, err := parseFields(dialect, value.Type())
+	if err != nil {
+		return nil, err
+	}
 
-	var pairs []ColumnValuePair
-	for i := 0; i < value.NumField(); i++ {
-		sf := value.Type().Field(i)
-		// check struct field is unexported
-		if sf.PkgPath != "" && !sf.Anonymous {
-			continue
-		}
-		c, err := ParseColumn(dialect, sf)
-		if err != nil {
-			return nil, err
+	pairs := make([]ColumnValuePair, 0, len(fields))
+	for _, f := range fields {
+		var v interface{}
+		if field, ok := fieldValue(value, f.index, false); ok {
+			v = field.Interface()
+			if t, ok := fieldType(f.sf, f.column.Options); ok {
+				if v, err = encodeValue(t, field); err != nil {
+					return nil, err
+				}
+			}
 		}
 		pairs = append(pairs, ColumnValuePair{
-			Column: c.Name,
-			Value:  value.Field(i).Interface(),
+			Column: f.column.Name,
+			Value:  v,
 		})
 	}
 
 	return pairs, nil
 }
+
+func ParseColumnPointers(dialect Dialect, model interface{}) (map[string]interface{}, error) {
+	value := reflect.ValueOf(model)
+	if value.Kind() != reflect.Ptr {
+		return nil, ErrRequirePointerType
+	}
+	value = unrefValueAndInit(value)
+	if value.Kind() != reflect.Struct {
+		return nil, ErrRequireStructType
+	}
+	fields, err := parseFields(dialect, value.Type())
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]interface{}, len(fields))
+	for _, f := range fields {
+		field, ok := fieldValue(value, f.index, true)
+		if !ok {
+			continue
+		}
+		if t, ok := fieldType(f.sf, f.column.Options); ok {
+			result[f.column.Name] = &typeScanner{t: t, dst: field}
+			continue
+		}
+		result[f.column.Name] = field.Addr().Interface()
+	}
+
+	return result, nil
+}
+
+// OptionFlatten flattens a named struct field into its columns, OptionPrefix flattens it with the prefix, e.g.
+//
+//	Address Address `orm:",flatten"`
+//	Billing Address `orm:",prefix=billing_"`
+//
+// anonymous struct fields are always flattened, and the prefix is optional.
+const (
+	OptionFlatten = "flatten"
+	OptionPrefix  = "prefix"
+)
+
+type structField struct {
+	sf     reflect.StructField
+	index  []int
+	column Column
+}
+
+// parseFields returns the column fields of rt with embedded structs flattened,
+// a shallower field wins over a deeper one with the same column name.
+func parseFields(dialect Dialect, rt reflect.Type) ([]structField, error) {
+	if dialect == nil {
+		dialect = GetDefaultDialect()
+	}
+	var (
+		fields []structField
+		depths []int
+		seen   = map[string]int{}
+	)
+	var walk func(rt reflect.Type, index []int, prefix string, depth int) error
+	walk = func(rt reflect.Type, index []int, prefix string, depth int) error {
+		for i := 0; i < rt.NumField(); i++ {
+			sf := rt.Field(i)
+			if sf.PkgPath != "" && !sf.Anonymous {
+				continue
+			}
+			if isRelationField(sf) || isSkippedField(sf) {
+				continue
+			}
+			fieldIndex := append(append([]int{}, index...), i)
+			items, options := parseTag(sf)
+			if isFlattenField(sf, items[0], options) {
+				ft := sf.Type
+				if ft.Kind() == reflect.Ptr {
+					ft = ft.Elem()
+				}
+				if err := walk(ft, fieldIndex, prefix+options[OptionPrefix], depth+1); err != nil {
+					return err
+				}
+				continue
+			}
+			if sf.PkgPath != "" {
+				continue
+			}
+			c, err := ParseColumn(dialect, sf)
+			if err != nil {
+				return err
+			}
+			c.Name = prefix + c.Name
+			if j, ok := seen[c.Name]; ok {
+				if depths[j] > depth {
+					fields[j] = structField{sf: sf, index: fieldIndex, column: c}
+					depths[j] = depth
+				}
+				continue
+			}
+			seen[c.Name] = len(fields)
+			fields = append(fields, structField{sf: sf, index: fieldIndex, column: c})
+			depths = append(depths, depth)
+		}
+		return nil
+	}
+	if err := walk(rt, nil, "", 0); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+func isSkippedField(sf reflect.StructField) bool {
+	return sf.Tag.Get(TagKey) == "-"
+}
+
+func isFlattenField(sf reflect.StructField, name string, options map[string]string) bool {
+	ft := sf.Type
+	if ft.Kind() == reflect.Ptr {
+		ft = ft.Elem()
+	}
+	if ft.Kind() != reflect.Struct || hasKey(options, OptionJSON) {
+		return false
+	}
+	if hasKey(options, OptionFlatten) || hasKey(options, OptionPrefix) {
+		return true
+	}
+	if !sf.Anonymous || name != "" {
+		return false
+	}
+	if _, ok := LookupType(ft); ok {
+		return false
+	}
+	return isStructValue(reflect.New(ft).Elem())
+}
+
+// fieldValue returns the field of value by index, nil embedded pointers are allocated if init is true.
+func fieldValue(value reflect.Value, index []int, init bool) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 && value.Kind() == reflect.Ptr {
+			if value.IsNil() {
+				if !init || !value.CanSet() {
+					return reflect.Value{}, false
+				}
+				value.Set(reflect.New(value.Type().Elem()))
+			}
+			value = value.Elem()
+		}
+		value = value.Field(x)
+	}
+	return value, true
+}
+
+func parseTag(sf reflect.StructField) ([]string, map[string]string) {
+	items := strings.Split(sf.Tag.Get(TagKey), " ")
+	options := map[string]string{}
+	parts := strings.Split(items[0], ",")
+	items[0] = parts[0]
+	for _, part := range parts[1:] {
+		if part == "" {
+			continue
+		}
+		k, v, _ := strings.Cut(part, "=")
+		options[k] = v
+	}
+	return items, options
+}
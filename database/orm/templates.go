@@ -21,7 +21,7 @@ func (t Template) Merge(others ...Template) Template {
 	newT := NewTemplate(t.Format, t.Values...)
 	for _, o := range others {
 		newT.Format += o.Format
-		newT.Values = append(o.Values, o.Values...)
+		newT.Values = append(newT.Values, o.Values...)
 	}
 	return newT
 }
@@ -0,0 +1,229 @@
+package orm
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// Tag options of timestamp columns, e.g.
+//
+//	CreatedAt time.Time  `orm:"created_at,create_time"`
+//	UpdatedAt time.Time  `orm:"updated_at,update_time"`
+//	DeletedAt *time.Time `orm:"deleted_at,soft_delete"`
+const (
+	OptionCreateTime = "create_time"
+	OptionUpdateTime = "update_time"
+	OptionSoftDelete = "soft_delete"
+)
+
+var NowFunc = time.Now
+
+func (b *Builder) Unscoped() *Builder {
+	if b.err != nil {
+		return b
+	}
+	b.unscoped = true
+	return b
+}
+
+func (b *Builder) DeleteModel(model interface{}) *Builder {
+	if b.err != nil {
+		return b
+	}
+	if err := b.setModel(model); err != nil {
+		b.err = err
+		return b
+	}
+	b.action = actionDelete
+	b.setTable(ParseTableName(model))
+	return b
+}
+
+var (
+	softDeletesMu sync.RWMutex
+	softDeletes   = map[string]string{}
+)
+
+// RegisterSoftDelete makes the builders of the table of model without a model, e.g. Delete("user"),
+// soft delete by column and skip the soft deleted rows, as the builders of models with a soft_delete column do,
+// model can be a table name.
+func RegisterSoftDelete(model interface{}, column string) {
+	softDeletesMu.Lock()
+	defer softDeletesMu.Unlock()
+	softDeletes[scopeTableName(model)] = column
+}
+
+func UnregisterSoftDelete(model interface{}) {
+	softDeletesMu.Lock()
+	defer softDeletesMu.Unlock()
+	delete(softDeletes, scopeTableName(model))
+}
+
+func (b *Builder) softDeleteColumn() (string, bool) {
+	if b.unscoped {
+		return "", false
+	}
+	if b.modelTable != nil {
+		for _, c := range b.modelTable.Columns {
+			if c.HasOption(OptionSoftDelete) {
+				return c.Name, true
+			}
+		}
+		return "", false
+	}
+	if b.scope.table == "" {
+		return "", false
+	}
+	softDeletesMu.RLock()
+	defer softDeletesMu.RUnlock()
+	column, ok := softDeletes[b.scope.table]
+	return column, ok
+}
+
+func (b *Builder) scopedWhere(ctx context.Context) (Condition, error) {
+	where := Condition{dialect: b.dialect, templates: append([]Template{}, b.where.templates...)}
+	switch b.action {
+	case actionSelect, actionUpdate, actionDelete:
+		if column, ok := b.softDeleteColumn(); ok {
+			if table := b.scope.qualifier(); table != "" {
+				column = table + "." + column
+			}
+			where.Appendf(fmt.Sprintf("%s is null", quoteColumn(b.dialect, column)))
+		}
+	}
+	if err := b.appendScopes(ctx, &where); err != nil {
+		return where, err
+	}
+	t, ok, err := b.versionWhere()
+	if err != nil {
+		return where, err
+	}
+	if ok {
+		where.Appendf(t.Format, t.Values...)
+	}
+	return where, nil
+}
+
+func (b *Builder) softDeleteTemplate() (Template, bool, error) {
+	column, ok := b.softDeleteColumn()
+	if !ok {
+		return Template{}, false, nil
+	}
+	var now interface{} = b.timestamp()
+	if b.model != nil {
+		values, err := b.timestampValues()
+		if err != nil {
+			return Template{}, false, err
+		}
+		now = values[column].Interface()
+	}
+	t := NewTemplate(fmt.Sprintf("update %s set %s = ?", b.table.Format, b.dialect.Quote(column)), now)
+	return t, true, nil
+}
+
+// timestamp returns the time of the timestamps, which is fixed during a build or exec of b.
+func (b *Builder) timestamp() time.Time {
+	if b.now.IsZero() {
+		return NowFunc()
+	}
+	return b.now
+}
+
+// timestampValues returns the values of the timestamp columns of the model that b writes,
+// which are the create and update times on insert, the update time on update and the delete time on soft delete.
+func (b *Builder) timestampValues() (map[string]reflect.Value, error) {
+	value := unrefValue(reflect.ValueOf(b.model))
+	result := map[string]reflect.Value{}
+	for _, c := range b.modelTable.Columns {
+		switch {
+		case c.HasOption(OptionCreateTime) && b.action == actionInsert,
+			c.HasOption(OptionUpdateTime) && (b.action == actionInsert || b.action == actionUpdate),
+			c.HasOption(OptionSoftDelete) && b.action == actionDelete:
+		default:
+			continue
+		}
+		field, err := fieldByColumn(b.dialect, value, c.Name)
+		if err != nil {
+			return nil, err
+		}
+		if c.HasOption(OptionCreateTime) && !field.IsZero() {
+			continue
+		}
+		v, err := timestampValue(field.Type(), b.timestamp())
+		if err != nil {
+			return nil, err
+		}
+		result[c.Name] = v
+	}
+	return result, nil
+}
+
+func (b *Builder) fillTimestamps(pairs []ColumnValuePair) ([]ColumnValuePair, error) {
+	values, err := b.timestampValues()
+	if err != nil {
+		return nil, err
+	}
+	options := make(map[string]Column, len(b.modelTable.Columns))
+	for _, c := range b.modelTable.Columns {
+		options[c.Name] = c
+	}
+	result := make([]ColumnValuePair, 0, len(pairs))
+	for _, pair := range pairs {
+		c := options[pair.Column]
+		isZero := pair.Value == nil || reflect.ValueOf(pair.Value).IsZero()
+		switch {
+		case c.HasOption(OptionSoftDelete):
+			if b.action == actionUpdate || isZero {
+				continue
+			}
+		case c.HasOption(OptionCreateTime) && b.action == actionUpdate:
+			continue
+		}
+		if v, ok := values[pair.Column]; ok {
+			pair.Value = v.Interface()
+		}
+		result = append(result, pair)
+	}
+	return result, nil
+}
+
+// writeTimestamps writes the timestamps of an executed model insert, update or soft delete back to the model.
+func (b *Builder) writeTimestamps() error {
+	if b.action == actionDelete {
+		if _, ok := b.softDeleteColumn(); !ok {
+			return nil
+		}
+	}
+	values, err := b.timestampValues()
+	if err != nil {
+		return err
+	}
+	model := unrefValue(reflect.ValueOf(b.model))
+	for column, v := range values {
+		field, err := fieldByColumn(b.dialect, model, column)
+		if err != nil {
+			return err
+		}
+		if field.CanSet() {
+			field.Set(v)
+		}
+	}
+	return nil
+}
+
+func timestampValue(rt reflect.Type, now time.Time) (reflect.Value, error) {
+	switch {
+	case rt == reflect.TypeOf(time.Time{}):
+		return reflect.ValueOf(now), nil
+	case rt == reflect.TypeOf(&time.Time{}):
+		return reflect.ValueOf(&now), nil
+	}
+	switch rt.Kind() {
+	case reflect.Int, reflect.Int64:
+		return reflect.ValueOf(now.Unix()).Convert(rt), nil
+	}
+	return reflect.Value{}, errorf("timestamp: unsupported type %s", rt)
+}
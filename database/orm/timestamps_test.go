@@ -0,0 +1,117 @@
+package orm
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func ExampleBuilder_DeleteModel() {
+	type User struct {
+		ID        int        `orm:"id"`
+		Name      string     `orm:"name"`
+		UpdatedAt int64      `orm:"updated_at,update_time"`
+		DeletedAt *time.Time `orm:"deleted_at,soft_delete"`
+	}
+	NowFunc = func() time.Time { return time.Unix(1600000000, 0).UTC() }
+	defer func() { NowFunc = time.Now }()
+
+	fmt.Println(New(&TestDialect{}).UpdateModel(&User{Name: "Medivh"}, true).Where("id = ?", 1).Build())
+	fmt.Println(New(&TestDialect{}).DeleteModel(&User{}).Where("id = ?", 1).Build())
+	fmt.Println(New(&TestDialect{}).DeleteModel(&User{}).Unscoped().Where("id = ?", 1).Build())
+	fmt.Println(New(&TestDialect{}).SelectModel(User{}).Build())
+	// output:
+	// "update 'User' set 'name' = ?,'updated_at' = ? where id = ? and 'User'.'deleted_at' is null": []interface {}{"Medivh", 1600000000, 1}
+	// "update 'User' set 'deleted_at' = ? where id = ? and 'User'.'deleted_at' is null": []interface {}{time.Date(2020, time.September, 13, 12, 26, 40, 0, time.UTC), 1}
+	// "delete from 'User' where id = ?": []interface {}{1}
+	// "select 'id','name','updated_at','deleted_at' from 'User' where 'User'.'deleted_at' is null"
+}
+
+func ExampleRegisterSoftDelete() {
+	NowFunc = func() time.Time { return time.Unix(1600000000, 0).UTC() }
+	defer func() { NowFunc = time.Now }()
+	RegisterSoftDelete("post", "deleted_at")
+	defer UnregisterSoftDelete("post")
+
+	fmt.Println(New(&TestDialect{}).Delete("post").Where("id = ?", 1).Build())
+	fmt.Println(New(&TestDialect{}).Delete("post").Unscoped().Where("id = ?", 1).Build())
+	fmt.Println(New(&TestDialect{}).Select("post").Build())
+	fmt.Println(New(&TestDialect{}).Select("post p", "p.id").Join("comment c", "c.post_id = p.id").Build())
+	fmt.Println(New(&TestDialect{}).Delete("comment").Build())
+	// output:
+	// "update 'post' set 'deleted_at' = ? where id = ? and 'post'.'deleted_at' is null": []interface {}{time.Date(2020, time.September, 13, 12, 26, 40, 0, time.UTC), 1}
+	// "delete from 'post' where id = ?": []interface {}{1}
+	// "select * from 'post' where 'post'.'deleted_at' is null"
+	// "select 'p'.'id' from 'post' p join 'comment' c on c.post_id = p.id where 'p'.'deleted_at' is null"
+	// "delete from 'comment'"
+}
+
+func TestTimestamps(t *testing.T) {
+	type User struct {
+		ID        int        `orm:"id"`
+		Name      string     `orm:"name"`
+		CreatedAt int64      `orm:"created_at,create_time"`
+		UpdatedAt int64      `orm:"updated_at,update_time"`
+		DeletedAt *time.Time `orm:"deleted_at,soft_delete"`
+	}
+	now := time.Unix(1600000000, 0).UTC()
+	NowFunc = func() time.Time {
+		now = now.Add(time.Second)
+		return now
+	}
+	defer func() { NowFunc = time.Now }()
+	ctx := context.Background()
+	_, db := openTestDB(t, `create table User (id integer primary key, name text, created_at integer, updated_at integer, deleted_at datetime)`)
+
+	u := &User{ID: 1, Name: "medivh"}
+	b := New(&sqliteTestDialect{}).InsertModel(u, false)
+	if built := b.Build(); built.Err != nil {
+		t.Fatal(built.Err)
+	}
+	if u.CreatedAt != 0 || u.UpdatedAt != 0 {
+		t.Fatalf("build wrote timestamps %d, %d into the model", u.CreatedAt, u.UpdatedAt)
+	}
+	if _, err := b.Exec(ctx, db); err != nil {
+		t.Fatal(err)
+	}
+	if created := now.Unix(); u.CreatedAt != created || u.UpdatedAt != created {
+		t.Fatalf("got timestamps %d, %d, want %d", u.CreatedAt, u.UpdatedAt, created)
+	}
+
+	created := u.CreatedAt
+	update := New(&sqliteTestDialect{}).UpdateModel(u, false, "ID").Where("id = ?", 1)
+	if _, err := update.Exec(ctx, db); err != nil {
+		t.Fatal(err)
+	}
+	if u.CreatedAt != created || u.UpdatedAt != now.Unix() || u.UpdatedAt == created {
+		t.Fatalf("got timestamps %d, %d after update", u.CreatedAt, u.UpdatedAt)
+	}
+	updated := u.UpdatedAt
+	if _, err := update.Exec(ctx, db); err != nil {
+		t.Fatal(err)
+	}
+	if u.UpdatedAt != now.Unix() || u.UpdatedAt == updated {
+		t.Fatalf("got updated at %d after reusing the builder, want %d", u.UpdatedAt, now.Unix())
+	}
+
+	if _, err := New(&sqliteTestDialect{}).DeleteModel(u).Where("id = ?", 1).Exec(ctx, db); err != nil {
+		t.Fatal(err)
+	}
+	if u.DeletedAt == nil || !u.DeletedAt.Equal(now) {
+		t.Fatalf("got deleted at %v, want %v", u.DeletedAt, now)
+	}
+	var users []User
+	if err := New(&sqliteTestDialect{}).SelectModel(User{}).Query(ctx, db, &users); err != nil {
+		t.Fatal(err)
+	}
+	if len(users) != 0 {
+		t.Fatalf("got soft deleted users %v", users)
+	}
+	if err := New(&sqliteTestDialect{}).SelectModel(User{}).Unscoped().Query(ctx, db, &users); err != nil {
+		t.Fatal(err)
+	}
+	if len(users) != 1 || users[0].DeletedAt == nil || users[0].UpdatedAt != u.UpdatedAt {
+		t.Fatalf("got users %v", users)
+	}
+}
@@ -0,0 +1,144 @@
+package orm
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func countTestUsers(t *testing.T, dbtx DBTX) []string {
+	t.Helper()
+	var names []string
+	if err := dbtx.Query(context.Background(), NewTemplate("select name from user order by id"), &names); err != nil {
+		t.Fatal(err)
+	}
+	return names
+}
+
+func insertTestUser(ctx context.Context, dbtx DBTX, name string) error {
+	_, err := dbtx.Exec(ctx, NewTemplate("insert into user(name) values(?)", name))
+	return err
+}
+
+func TestNestedTx(t *testing.T) {
+	_, dbtx := openTestDB(t, "create table user (id integer primary key, name text)")
+	ctx := context.Background()
+	errRollback := errors.New("rollback")
+	err := dbtx.Tx(ctx, func(ctx context.Context, tx DBTX) error {
+		if err := insertTestUser(ctx, tx, "a"); err != nil {
+			return err
+		}
+		// a rolled back savepoint is released, so the next one can reuse its name
+		for _, name := range []string{"b", "c"} {
+			err := tx.Tx(ctx, func(ctx context.Context, tx DBTX) error {
+				if err := insertTestUser(ctx, tx, name); err != nil {
+					return err
+				}
+				if name == "b" {
+					return errRollback
+				}
+				return tx.Tx(ctx, func(ctx context.Context, tx DBTX) error {
+					return insertTestUser(ctx, tx, name+"2")
+				})
+			})
+			if err != nil && !errors.Is(err, errRollback) {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := fmt.Sprint(countTestUsers(t, dbtx)); got != "[a c c2]" {
+		t.Fatalf("got %s", got)
+	}
+
+	err = dbtx.Tx(ctx, func(ctx context.Context, tx DBTX) error {
+		if err := tx.Tx(ctx, func(ctx context.Context, tx DBTX) error {
+			return insertTestUser(ctx, tx, "d")
+		}); err != nil {
+			return err
+		}
+		return errRollback
+	})
+	if !errors.Is(err, errRollback) {
+		t.Fatal(err)
+	}
+	if got := fmt.Sprint(countTestUsers(t, dbtx)); got != "[a c c2]" {
+		t.Fatalf("committed savepoint survived outer rollback: %s", got)
+	}
+	err = dbtx.Tx(ctx, func(ctx context.Context, tx DBTX) error {
+		if err := tx.Tx(ctx, func(ctx context.Context, tx DBTX) error { return nil }, nil); err != nil {
+			return err
+		}
+		return tx.Tx(ctx, func(ctx context.Context, tx DBTX) error {
+			return insertTestUser(ctx, tx, "e")
+		}, &sql.TxOptions{ReadOnly: true})
+	}, &sql.TxOptions{Isolation: sql.LevelSerializable})
+	if !errors.Is(err, ErrNestedTxOptions) {
+		t.Fatalf("got error %v, want %v", err, ErrNestedTxOptions)
+	}
+	if got := fmt.Sprint(countTestUsers(t, dbtx)); got != "[a c c2]" {
+		t.Fatalf("got %s after nested options were rejected", got)
+	}
+}
+
+type testSerializationError struct{}
+
+func (testSerializationError) Error() string    { return "could not serialize access" }
+func (testSerializationError) SQLState() string { return "40001" }
+
+func TestTxRetry(t *testing.T) {
+	_, dbtx := openTestDB(t, "create table user (id integer primary key, name text)")
+	ctx := context.Background()
+	attempts := 0
+	err := TxRetry(ctx, dbtx, func(ctx context.Context, tx DBTX) error {
+		attempts++
+		if err := insertTestUser(ctx, tx, fmt.Sprintf("attempt%d", attempts)); err != nil {
+			return err
+		}
+		if attempts < 3 {
+			return fmt.Errorf("insert: %w", testSerializationError{})
+		}
+		return nil
+	}, RetryOptions{MaxAttempts: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := fmt.Sprint(attempts, countTestUsers(t, dbtx)); got != "3 [attempt3]" {
+		t.Fatalf("got %s", got)
+	}
+
+	// inside a transaction only the outer transaction may retry
+	attempts = 0
+	err = dbtx.Tx(ctx, func(ctx context.Context, tx DBTX) error {
+		return TxRetry(ctx, tx, func(ctx context.Context, tx DBTX) error {
+			attempts++
+			return testSerializationError{}
+		})
+	})
+	if !IsRetryable(DialectOf(dbtx), err) || attempts != 1 {
+		t.Fatalf("got %d attempts, err %v", attempts, err)
+	}
+}
+
+// testMySQLError has the fields of mysql.MySQLError.
+type testMySQLError struct {
+	Number   uint16
+	SQLState [5]byte
+	Message  string
+}
+
+func (e *testMySQLError) Error() string { return e.Message }
+
+func ExampleSQLState() {
+	err := fmt.Errorf("exec: %w", &testMySQLError{Number: 1213, SQLState: [5]byte{'4', '0', '0', '0', '1'}})
+	fmt.Println(SQLState(err), IsRetryable(&TestDialect{}, err))
+	fmt.Println(SQLState(errors.New("x")), IsRetryable(&TestDialect{}, errors.New("x")))
+	// output:
+	// 40001 true
+	//  false
+}
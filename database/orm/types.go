@@ -0,0 +1,278 @@
+package orm
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding"
+	"encoding/json"
+	"net"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// OptionJSON stores the field as json text, e.g.
+//
+//	Settings map[string]string `orm:"settings,json"`
+const OptionJSON = "json"
+
+// Type encodes values of a Go type to database values and decodes them back.
+type Type interface {
+	// StorageType is the Go type whose dialect mapping is used as the column type.
+	StorageType() reflect.Type
+	Encode(value reflect.Value) (interface{}, error)
+	Decode(src interface{}, dst reflect.Value) error
+}
+
+// JSONDialect is implemented by dialects with a native json column type.
+type JSONDialect interface {
+	Dialect
+	JSONType() string
+}
+
+var types sync.Map
+
+func RegisterType(rt reflect.Type, t Type) {
+	types.Store(rt, t)
+}
+
+// RegisterTypeFunc registers T stored as S with the encode and decode functions.
+func RegisterTypeFunc[T any, S any](encode func(T) (S, error), decode func(S) (T, error)) {
+	RegisterType(reflect.TypeOf((*T)(nil)).Elem(), funcType[T, S]{encode: encode, decode: decode})
+}
+
+// LookupType returns the registered Type of rt, or a text Type when rt implements
+// encoding.TextMarshaler and encoding.TextUnmarshaler but not driver.Valuer and sql.Scanner.
+func LookupType(rt reflect.Type) (Type, bool) {
+	if v, ok := types.Load(rt); ok {
+		return v.(Type), true
+	}
+	if rt == reflect.TypeOf(time.Time{}) {
+		return nil, false
+	}
+	ptr := reflect.PtrTo(rt)
+	if rt.Implements(valuerType) || ptr.Implements(valuerType) || ptr.Implements(scannerType) {
+		return nil, false
+	}
+	if rt.Implements(textMarshalerType) && ptr.Implements(textUnmarshalerType) {
+		return textType{}, true
+	}
+	return nil, false
+}
+
+var (
+	valuerType          = reflect.TypeOf((*driver.Valuer)(nil)).Elem()
+	scannerType         = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+	textMarshalerType   = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	stringType          = reflect.TypeOf("")
+)
+
+func init() {
+	RegisterTypeFunc(func(ip net.IP) (string, error) {
+		if ip == nil {
+			return "", nil
+		}
+		return ip.String(), nil
+	}, func(s string) (net.IP, error) {
+		if s == "" {
+			return nil, nil
+		}
+		ip := net.ParseIP(s)
+		if ip == nil {
+			return nil, errorf("invalid ip %q", s)
+		}
+		return ip, nil
+	})
+}
+
+func fieldType(sf reflect.StructField, options map[string]string) (Type, bool) {
+	if hasKey(options, OptionJSON) {
+		return jsonType{}, true
+	}
+	rt := sf.Type
+	if rt.Kind() == reflect.Ptr {
+		rt = rt.Elem()
+	}
+	return LookupType(rt)
+}
+
+func mappingFieldType(dialect Dialect, sf reflect.StructField, options map[string]string) string {
+	if hasKey(options, OptionJSON) {
+		if d, ok := dialect.(JSONDialect); ok {
+			return d.JSONType()
+		}
+		return dialect.MappingType(stringType)
+	}
+	if t, ok := fieldType(sf, options); ok {
+		return dialect.MappingType(t.StorageType())
+	}
+	return dialect.MappingType(sf.Type)
+}
+
+func encodeValue(t Type, value reflect.Value) (interface{}, error) {
+	switch value.Kind() {
+	case reflect.Ptr:
+		if value.IsNil() {
+			return nil, nil
+		}
+		value = value.Elem()
+	case reflect.Slice, reflect.Map:
+		if value.IsNil() {
+			return nil, nil
+		}
+	}
+	return t.Encode(value)
+}
+
+// typeScanner decodes a scanned value into dst with t, dst may be a pointer which is set to nil on null.
+type typeScanner struct {
+	t   Type
+	dst reflect.Value
+}
+
+func (s *typeScanner) Scan(src interface{}) error {
+	dst := s.dst
+	if dst.Kind() == reflect.Ptr {
+		if src == nil {
+			dst.Set(reflect.Zero(dst.Type()))
+			return nil
+		}
+		if dst.IsNil() {
+			dst.Set(reflect.New(dst.Type().Elem()))
+		}
+		dst = dst.Elem()
+	}
+	if src == nil {
+		dst.Set(reflect.Zero(dst.Type()))
+		return nil
+	}
+	return s.t.Decode(src, dst)
+}
+
+func scanTarget(dst interface{}) interface{} {
+	value := reflect.ValueOf(dst)
+	if value.Kind() != reflect.Ptr || value.IsNil() {
+		return dst
+	}
+	rt := value.Type().Elem()
+	if rt.Kind() == reflect.Ptr {
+		rt = rt.Elem()
+	}
+	if t, ok := LookupType(rt); ok {
+		return &typeScanner{t: t, dst: value.Elem()}
+	}
+	return dst
+}
+
+type funcType[T any, S any] struct {
+	encode func(T) (S, error)
+	decode func(S) (T, error)
+}
+
+func (t funcType[T, S]) StorageType() reflect.Type {
+	return reflect.TypeOf((*S)(nil)).Elem()
+}
+
+func (t funcType[T, S]) Encode(value reflect.Value) (interface{}, error) {
+	return t.encode(value.Interface().(T))
+}
+
+func (t funcType[T, S]) Decode(src interface{}, dst reflect.Value) error {
+	var s S
+	sv := reflect.ValueOf(&s).Elem()
+	srcValue := reflect.ValueOf(src)
+	if !canConvertStorage(srcValue.Type(), sv.Type()) {
+		return errorf("decode: can not convert %T to %s", src, sv.Type())
+	}
+	sv.Set(srcValue.Convert(sv.Type()))
+	v, err := t.decode(s)
+	if err != nil {
+		return err
+	}
+	dst.Set(reflect.ValueOf(&v).Elem())
+	return nil
+}
+
+// canConvertStorage reports whether a value scanned as from can be converted to the storage type to,
+// numbers convert to numbers and strings to bytes and back, but never numbers to strings, e.g. int64 65 to "A".
+func canConvertStorage(from, to reflect.Type) bool {
+	if !from.ConvertibleTo(to) {
+		return false
+	}
+	switch {
+	case from.Kind() == to.Kind():
+		return true
+	case isNumberKind(from.Kind()) && isNumberKind(to.Kind()):
+		return true
+	case isTextType(from) && isTextType(to):
+		return true
+	}
+	return false
+}
+
+func isNumberKind(k reflect.Kind) bool {
+	switch k {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+func isTextType(rt reflect.Type) bool {
+	return rt.Kind() == reflect.String || (rt.Kind() == reflect.Slice && rt.Elem().Kind() == reflect.Uint8)
+}
+
+type jsonType struct{}
+
+func (jsonType) StorageType() reflect.Type {
+	return stringType
+}
+
+func (jsonType) Encode(value reflect.Value) (interface{}, error) {
+	b, err := json.Marshal(value.Interface())
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+func (jsonType) Decode(src interface{}, dst reflect.Value) error {
+	switch v := src.(type) {
+	case []byte:
+		return json.Unmarshal(v, dst.Addr().Interface())
+	case string:
+		return json.Unmarshal([]byte(v), dst.Addr().Interface())
+	default:
+		return errorf("decode json: unsupported type %T", src)
+	}
+}
+
+type textType struct{}
+
+func (textType) StorageType() reflect.Type {
+	return stringType
+}
+
+func (textType) Encode(value reflect.Value) (interface{}, error) {
+	b, err := value.Interface().(encoding.TextMarshaler).MarshalText()
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+func (textType) Decode(src interface{}, dst reflect.Value) error {
+	var b []byte
+	switch v := src.(type) {
+	case []byte:
+		b = v
+	case string:
+		b = []byte(v)
+	default:
+		return errorf("decode text: unsupported type %T", src)
+	}
+	return dst.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText(b)
+}
@@ -0,0 +1,178 @@
+package orm
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func ExampleParseColumnValuePairs() {
+	type Host struct {
+		IP      net.IP            `orm:"ip"`
+		Timeout time.Duration     `orm:"timeout"`
+		Labels  map[string]string `orm:"labels,json"`
+	}
+	t, err := ParseTable(&TestDialect{}, Host{})
+	if err != nil {
+		panic(err)
+	}
+	for _, c := range t.Columns {
+		fmt.Println(c.Name, c.Type)
+	}
+	pairs, err := ParseColumnValuePairs(&TestDialect{}, Host{
+		IP:      net.ParseIP("10.0.0.1"),
+		Timeout: time.Second,
+		Labels:  map[string]string{"env": "prod"},
+	})
+	if err != nil {
+		panic(err)
+	}
+	fmt.Printf("%#v\n", pairs)
+	// output:
+	// ip text
+	// timeout integer
+	// labels text
+	// []orm.ColumnValuePair{orm.ColumnValuePair{Column:"ip", Value:"10.0.0.1"}, orm.ColumnValuePair{Column:"timeout", Value:1000000000}, orm.ColumnValuePair{Column:"labels", Value:"{\"env\":\"prod\"}"}}
+}
+
+type testLevel int
+
+var testLevels = []string{"debug", "info", "warn"}
+
+func encodeTestLevel(l testLevel) (string, error) {
+	return testLevels[l], nil
+}
+
+func decodeTestLevel(s string) (testLevel, error) {
+	for i, l := range testLevels {
+		if l == s {
+			return testLevel(i), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown level %q", s)
+}
+
+func ExampleRegisterTypeFunc() {
+	RegisterTypeFunc(encodeTestLevel, decodeTestLevel)
+
+	raw, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		panic(err)
+	}
+	defer raw.Close()
+	raw.SetMaxOpenConns(1)
+	if _, err := raw.Exec(`create table "log" (id integer, level text)`); err != nil {
+		panic(err)
+	}
+	type Log struct {
+		ID    int       `orm:"id"`
+		Level testLevel `orm:"level"`
+	}
+	ctx := context.Background()
+	dialect := &sqliteTestDialect{}
+	db := NewDB(dialect, raw)
+	for _, l := range []Log{{1, 1}, {2, 2}} {
+		if _, err := New(dialect).InsertModel(l, false).Exec(ctx, db); err != nil {
+			panic(err)
+		}
+	}
+	var stored []string
+	if err := db.Query(ctx, NewTemplate(`select level from "log" order by id`), &stored); err != nil {
+		panic(err)
+	}
+	var logs []Log
+	if err := New(dialect).SelectModel(Log{}).Query(ctx, db, &logs); err != nil {
+		panic(err)
+	}
+	var level testLevel
+	err = db.Query(ctx, NewTemplate(`select 'trace'`), &level)
+	fmt.Println(stored)
+	fmt.Println(logs)
+	fmt.Println(err)
+	// output:
+	// [info warn]
+	// [{1 1} {2 2}]
+	// sql: Scan error on column index 0, name "'trace'": unknown level "trace"
+}
+
+func TestFuncType_Decode(t *testing.T) {
+	level := funcType[testLevel, string]{encode: encodeTestLevel, decode: decodeTestLevel}
+	seconds := funcType[time.Duration, int32]{
+		encode: func(d time.Duration) (int32, error) { return int32(d / time.Second), nil },
+		decode: func(n int32) (time.Duration, error) { return time.Duration(n) * time.Second, nil },
+	}
+	tests := []struct {
+		t    Type
+		src  interface{}
+		want interface{}
+		err  string
+	}{
+		{level, "info", testLevel(1), ""},
+		{level, []byte("warn"), testLevel(2), ""},
+		{level, int64(65), testLevel(0), "orm: decode: can not convert int64 to string"},
+		{seconds, int64(3), 3 * time.Second, ""},
+		{seconds, float64(2), 2 * time.Second, ""},
+		{seconds, "1", time.Duration(0), "orm: decode: can not convert string to int32"},
+	}
+	for _, tt := range tests {
+		dst := reflect.New(reflect.TypeOf(tt.want)).Elem()
+		err := tt.t.Decode(tt.src, dst)
+		if (err == nil) != (tt.err == "") || (err != nil && err.Error() != tt.err) {
+			t.Errorf("%#v: got error %v, want %q", tt.src, err, tt.err)
+			continue
+		}
+		if got := dst.Interface(); got != tt.want {
+			t.Errorf("%#v: got %v, want %v", tt.src, got, tt.want)
+		}
+	}
+}
+
+func TestTypes_Read(t *testing.T) {
+	type Host struct {
+		ID     int               `orm:"id"`
+		IP     net.IP            `orm:"ip"`
+		Backup *net.IP           `orm:"backup"`
+		Labels map[string]string `orm:"labels,json"`
+	}
+	ctx := context.Background()
+	_, db := openTestDB(t, `create table "host" (id integer, ip text, backup text, labels text)`)
+	hosts := []Host{
+		{ID: 1, IP: net.ParseIP("10.0.0.1"), Labels: map[string]string{"env": "prod"}},
+		{ID: 2, IP: net.ParseIP("::1"), Backup: &net.IP{}},
+	}
+	*hosts[1].Backup = net.ParseIP("10.0.0.2")
+	for _, h := range hosts {
+		if _, err := New(&sqliteTestDialect{}).InsertModel(h, false).Exec(ctx, db); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var got []Host
+	if err := New(&sqliteTestDialect{}).SelectModel(Host{}).OrderBy("id").Query(ctx, db, &got); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, hosts) {
+		t.Fatalf("got %+v, want %+v", got, hosts)
+	}
+
+	rows, err := QueryRows(ctx, db, NewTemplate(`select id, ip, backup, labels from "host" where id = ?`, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var one Host
+	if err := rows.Struct(&one); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(one, hosts[0]) {
+		t.Fatalf("got %+v, want %+v", one, hosts[0])
+	}
+
+	var ip net.IP
+	if err := db.Query(ctx, NewTemplate(`select 'not an ip'`), &ip); err == nil {
+		t.Fatal("got nil error for an invalid ip")
+	}
+}
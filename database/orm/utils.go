@@ -1,8 +1,11 @@
 package orm
 
 import (
+	"database/sql"
 	"reflect"
+	"sort"
 	"strings"
+	"time"
 	"unicode"
 )
 
@@ -22,6 +25,15 @@ func containStrings(source []string, target ...string) bool {
 	return true
 }
 
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
 func repeatString(s string, n int) []string {
 	r := make([]string, 0, n)
 	for i := 0; i < n; i++ {
@@ -152,3 +164,18 @@ func unrefValueAndInit(v reflect.Value) reflect.Value {
 	}
 	return v
 }
+
+func isStructValue(v reflect.Value) bool {
+	if v.Kind() != reflect.Struct {
+		return false
+	}
+	if v.CanAddr() {
+		if _, ok := v.Addr().Interface().(sql.Scanner); ok {
+			return false
+		}
+	}
+	if _, ok := v.Interface().(time.Time); ok {
+		return false
+	}
+	return true
+}
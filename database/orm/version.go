@@ -0,0 +1,129 @@
+package orm
+
+import (
+	"database/sql"
+	"fmt"
+	"reflect"
+)
+
+// OptionVersion enables optimistic locking on an integer column, e.g.
+//
+//	Version int64 `orm:"version,version"`
+//
+// model updates check the version and increment it, returning ErrStaleObject when the row has changed.
+const OptionVersion = "version"
+
+var ErrStaleObject = errorf("stale object")
+
+type StaleObjectError struct {
+	Table   string
+	Version int64
+}
+
+func (e *StaleObjectError) Error() string {
+	return fmt.Sprintf("%sstale object: table %s, version %d", errorPrefix, e.Table, e.Version)
+}
+
+func (e *StaleObjectError) Is(target error) bool {
+	return target == ErrStaleObject
+}
+
+func (b *Builder) versionColumn() (string, bool, error) {
+	if b.modelTable == nil {
+		return "", false, nil
+	}
+	for _, c := range b.modelTable.Columns {
+		if c.HasOption(OptionVersion) {
+			return c.Name, true, nil
+		}
+	}
+	return "", false, nil
+}
+
+func (b *Builder) versionField(column string) (reflect.Value, int64, error) {
+	field, err := fieldByColumn(b.dialect, unrefValue(reflect.ValueOf(b.model)), column)
+	if err != nil {
+		return reflect.Value{}, 0, err
+	}
+	switch field.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return field, field.Int(), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return field, int64(field.Uint()), nil
+	}
+	return reflect.Value{}, 0, errorf("version: unsupported type %s", field.Type())
+}
+
+// fillVersion sets the version column to the next version on update, and to 1 on insert if it's zero.
+func (b *Builder) fillVersion(pairs []ColumnValuePair) ([]ColumnValuePair, error) {
+	if b.action != actionInsert && b.action != actionUpdate {
+		return pairs, nil
+	}
+	column, ok, err := b.versionColumn()
+	if err != nil || !ok {
+		return pairs, err
+	}
+	field, version, err := b.versionField(column)
+	if err != nil {
+		return nil, err
+	}
+	for i, pair := range pairs {
+		if pair.Column != column {
+			continue
+		}
+		switch {
+		case b.action == actionUpdate:
+			pairs[i].Value = reflect.ValueOf(version + 1).Convert(field.Type()).Interface()
+		case version == 0:
+			pairs[i].Value = reflect.ValueOf(int64(1)).Convert(field.Type()).Interface()
+		}
+	}
+	return pairs, nil
+}
+
+func (b *Builder) versionWhere() (Template, bool, error) {
+	if b.action != actionUpdate {
+		return Template{}, false, nil
+	}
+	column, ok, err := b.versionColumn()
+	if err != nil || !ok {
+		return Template{}, false, err
+	}
+	field, _, err := b.versionField(column)
+	if err != nil {
+		return Template{}, false, err
+	}
+	return NewTemplate(fmt.Sprintf("%s = ?", b.dialect.Quote(column)), field.Interface()), true, nil
+}
+
+// checkVersion returns ErrStaleObject if no row is updated, otherwise writes the version back to the model.
+func (b *Builder) checkVersion(result sql.Result) error {
+	if b.action != actionInsert && b.action != actionUpdate {
+		return nil
+	}
+	column, ok, err := b.versionColumn()
+	if err != nil || !ok {
+		return err
+	}
+	field, version, err := b.versionField(column)
+	if err != nil {
+		return err
+	}
+	if b.action == actionInsert {
+		if version == 0 && field.CanSet() {
+			field.Set(reflect.ValueOf(int64(1)).Convert(field.Type()))
+		}
+		return nil
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return &StaleObjectError{Table: ParseTableName(b.model), Version: version}
+	}
+	if field.CanSet() {
+		field.Set(reflect.ValueOf(version + 1).Convert(field.Type()))
+	}
+	return nil
+}
@@ -0,0 +1,17 @@
+package orm
+
+import "fmt"
+
+func ExampleBuilder_UpdateModel_version() {
+	type Doc struct {
+		Title   string `orm:"title"`
+		Version int    `orm:"version,version"`
+	}
+	t := New(&TestDialect{}).
+		UpdateModel(&Doc{Title: "Go", Version: 3}, false).
+		Where("title = ?", "Go").
+		Build()
+	fmt.Println(t)
+	// output:
+	// "update 'Doc' set 'title' = ?,'version' = ? where title = ? and 'version' = ?": []interface {}{"Go", 4, "Go", 3}
+}
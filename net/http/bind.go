@@ -0,0 +1,285 @@
+package http
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/medivhyang/golib/reflect/binding"
+)
+
+// Tag keys of Request.Bind, e.g.
+//
+//	ID    int    `path:"id"`
+//	Q     string `query:"q"`
+//	Token string `header:"X-Token" validate:"required"`
+//	Name  string `json:"name" validate:"required,max=32"`
+//	Email string `json:"email" validate:"omitempty,email"`
+const (
+	PathTagKey     = "path"
+	QueryTagKey    = "query"
+	HeaderTagKey   = "header"
+	ValidateTagKey = "validate"
+)
+
+const (
+	MIMEForm          = "application/x-www-form-urlencoded"
+	MIMEMultipartForm = "multipart/form-data"
+	MIMETextXML       = "text/xml"
+)
+
+var DefaultMultipartMemory int64 = 32 << 20
+
+// DecodeFunc decodes the body of r into i.
+type DecodeFunc func(r *http.Request, i interface{}) error
+
+var (
+	decodersMutex sync.RWMutex
+	decoders      = map[string]DecodeFunc{
+		MIMEJSON:          decodeJSON,
+		MIMEXML:           decodeXML,
+		MIMETextXML:       decodeXML,
+		MIMEForm:          decodeForm,
+		MIMEMultipartForm: decodeMultipartForm,
+	}
+)
+
+// RegisterDecoder registers the body decoder of a media type, e.g. "application/msgpack".
+func RegisterDecoder(mediaType string, f DecodeFunc) {
+	decodersMutex.Lock()
+	defer decodersMutex.Unlock()
+	decoders[strings.ToLower(mediaType)] = f
+}
+
+func lookupDecoder(mediaType string) (DecodeFunc, bool) {
+	decodersMutex.RLock()
+	defer decodersMutex.RUnlock()
+	f, ok := decoders[mediaType]
+	return f, ok
+}
+
+// BindError is returned by Request.Bind, Status is 400 for malformed requests,
+// 415 for unsupported content types and 422 for validation failures.
+type BindError struct {
+	Status  int          `json:"-"`
+	Message string       `json:"message"`
+	Fields  []FieldError `json:"fields,omitempty"`
+	Err     error        `json:"-"`
+}
+
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule,omitempty"`
+	Message string `json:"message"`
+}
+
+func (e *BindError) Error() string {
+	builder := strings.Builder{}
+	builder.WriteString(errorPrefix)
+	builder.WriteString(e.Message)
+	for i, f := range e.Fields {
+		if i == 0 {
+			builder.WriteString(": ")
+		} else {
+			builder.WriteString("; ")
+		}
+		builder.WriteString(f.Field + " " + f.Message)
+	}
+	return builder.String()
+}
+
+func (e *BindError) Unwrap() error {
+	return e.Err
+}
+
+// Bind decodes the body by Content-Type into i, then binds the path, query and header tags and validates i.
+func (r *Request) Bind(i interface{}) error {
+	rv := reflect.ValueOf(i)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return ErrRequirePointerType
+	}
+	if err := r.decodeBody(i); err != nil {
+		return err
+	}
+	if err := r.bindTags(i); err != nil {
+		return err
+	}
+	return Validate(i)
+}
+
+func (r *Request) decodeBody(i interface{}) error {
+	if r.raw.Body == nil || r.raw.Body == http.NoBody || r.raw.ContentLength == 0 {
+		return nil
+	}
+	contentType := r.raw.Header.Get(HeaderContentType)
+	if contentType == "" {
+		return nil
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return &BindError{Status: http.StatusBadRequest, Message: "invalid content type", Err: err}
+	}
+	decode, ok := lookupDecoder(mediaType)
+	if !ok {
+		return &BindError{Status: http.StatusUnsupportedMediaType, Message: fmt.Sprintf("unsupported content type %q", mediaType)}
+	}
+	if err := decode(r.raw, i); err != nil {
+		var bindErr *BindError
+		if errors.As(err, &bindErr) {
+			return err
+		}
+		return &BindError{Status: http.StatusBadRequest, Message: "invalid body", Err: err}
+	}
+	return nil
+}
+
+func (r *Request) bindTags(i interface{}) error {
+	rv := reflect.ValueOf(i).Elem()
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			rv.Set(reflect.New(rv.Type().Elem()))
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil
+	}
+	var (
+		query  = r.raw.URL.Query()
+		params = Params(r.raw)
+	)
+	var fields []FieldError
+	rt := rv.Type()
+	for j := 0; j < rt.NumField(); j++ {
+		sf := rt.Field(j)
+		if sf.PkgPath != "" {
+			continue
+		}
+		var values []string
+		if name, ok := sf.Tag.Lookup(HeaderTagKey); ok {
+			if vv := r.raw.Header.Values(name); len(vv) > 0 {
+				values = vv
+			}
+		}
+		if name, ok := sf.Tag.Lookup(QueryTagKey); ok {
+			if vv := query[name]; len(vv) > 0 {
+				values = vv
+			}
+		}
+		if name, ok := sf.Tag.Lookup(PathTagKey); ok {
+			if v, ok := params[name]; ok {
+				values = []string{v}
+			}
+		}
+		if len(values) == 0 {
+			continue
+		}
+		if err := bindValues(rv.Field(j), values); err != nil {
+			fields = append(fields, FieldError{Field: fieldName(sf), Message: err.Error()})
+		}
+	}
+	if len(fields) > 0 {
+		return &BindError{Status: http.StatusBadRequest, Message: "invalid params", Fields: fields}
+	}
+	return nil
+}
+
+func bindValues(fv reflect.Value, values []string) error {
+	if fv.Kind() == reflect.Ptr && fv.IsNil() {
+		fv.Set(reflect.New(fv.Type().Elem()))
+	}
+	switch unrefType(fv.Type()).Kind() {
+	case reflect.Slice, reflect.Array:
+		return binding.BindList(values, fv.Addr().Interface())
+	}
+	return binding.Bind(values[0], fv.Addr().Interface())
+}
+
+func decodeJSON(r *http.Request, i interface{}) error {
+	return json.NewDecoder(r.Body).Decode(i)
+}
+
+func decodeXML(r *http.Request, i interface{}) error {
+	return xml.NewDecoder(r.Body).Decode(i)
+}
+
+func decodeForm(r *http.Request, i interface{}) error {
+	if err := r.ParseForm(); err != nil {
+		return err
+	}
+	return bindForm(r.PostForm, nil, i)
+}
+
+func decodeMultipartForm(r *http.Request, i interface{}) error {
+	if err := r.ParseMultipartForm(DefaultMultipartMemory); err != nil {
+		return err
+	}
+	return bindForm(r.MultipartForm.Value, r.MultipartForm.File, i)
+}
+
+// bindForm binds values like BindForm, and files to *multipart.FileHeader or []*multipart.FileHeader fields.
+func bindForm(values map[string][]string, files map[string][]*multipart.FileHeader, i interface{}) error {
+	if unrefType(reflect.TypeOf(i)).Kind() != reflect.Struct {
+		return ErrRequireStructType
+	}
+	m := parseStructTagFirstWord(i, BindingTagKey)
+	name := func(s string) string {
+		if v, ok := m[s]; ok {
+			return v
+		}
+		return toCase(caseSnake, s)
+	}
+	if err := binding.BindStructFunc(func(s string) []string {
+		return values[name(s)]
+	}, i); err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		return nil
+	}
+	rv := reflect.ValueOf(i)
+	for rv.Kind() == reflect.Ptr {
+		rv = rv.Elem()
+	}
+	fileType := reflect.TypeOf(&multipart.FileHeader{})
+	for j := 0; j < rv.NumField(); j++ {
+		sf := rv.Type().Field(j)
+		fhs := files[name(sf.Name)]
+		if sf.PkgPath != "" || len(fhs) == 0 {
+			continue
+		}
+		switch sf.Type {
+		case fileType:
+			rv.Field(j).Set(reflect.ValueOf(fhs[0]))
+		case reflect.SliceOf(fileType):
+			rv.Field(j).Set(reflect.ValueOf(fhs))
+		}
+	}
+	return nil
+}
+
+// fieldName returns the name of sf in requests, which is used in field errors.
+func fieldName(sf reflect.StructField) string {
+	for _, key := range []string{PathTagKey, QueryTagKey, HeaderTagKey, "json", "xml", BindingTagKey} {
+		if v, ok := sf.Tag.Lookup(key); ok {
+			if name := strings.Split(strings.Split(v, ",")[0], " ")[0]; name != "" && name != "-" {
+				return name
+			}
+		}
+	}
+	return sf.Name
+}
+
+func unrefType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
+}
@@ -0,0 +1,88 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+)
+
+func ExampleRequest_Bind() {
+	type createPost struct {
+		UserID int      `path:"id"`
+		Draft  bool     `query:"draft"`
+		Token  string   `header:"X-Token" validate:"required"`
+		Title  string   `json:"title" binding:"title" validate:"required,max=10"`
+		Tags   []string `json:"tags" validate:"omitempty,max=2"`
+		Status string   `json:"status" validate:"omitempty,oneof=draft published"`
+	}
+	router := NewRouter()
+	router.Post("/users/:id<int>/posts", func(w *ResponseWriter, r *Request) {
+		var v createPost
+		if err := r.Bind(&v); err != nil {
+			var bindErr *BindError
+			if errors.As(err, &bindErr) {
+				w.JSON(bindErr.Status, bindErr)
+				return
+			}
+			w.Text(http.StatusInternalServerError, err.Error())
+			return
+		}
+		w.JSON(http.StatusOK, v)
+	})
+	do := func(contentType string, body string) {
+		req := httptest.NewRequest(http.MethodPost, "/users/42/posts?draft=true", strings.NewReader(body))
+		req.Header.Set(HeaderContentType, contentType)
+		req.Header.Set("X-Token", "secret")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		fmt.Print(w.Code, " ", w.Body.String())
+	}
+
+	do("application/json; charset=utf-8", `{"title":"hello","tags":["a"]}`)
+	do(MIMEForm, "title=hello")
+	do(MIMEJSON, `{"title":"hello world!","tags":["a","b","c"],"status":"x"}`)
+	do(MIMEJSON, `{"title":`)
+	do("application/msgpack", `x`)
+
+	// Output:
+	// 200 {"UserID":42,"Draft":true,"Token":"secret","title":"hello","tags":["a"],"status":""}
+	// 200 {"UserID":42,"Draft":true,"Token":"secret","title":"hello","tags":null,"status":""}
+	// 422 {"message":"validation failed","fields":[{"field":"title","rule":"max","message":"must be at most 10"},{"field":"tags","rule":"max","message":"must be at most 2"},{"field":"status","rule":"oneof","message":"must be one of draft published"}]}
+	// 400 {"message":"invalid body"}
+	// 415 {"message":"unsupported content type \"application/msgpack\""}
+}
+
+func ExampleRegisterDecoder() {
+	RegisterDecoder("application/x-json-lines", func(r *http.Request, i interface{}) error {
+		return json.NewDecoder(r.Body).Decode(i)
+	})
+	var v struct {
+		Name string `json:"name"`
+	}
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"name":"medivh"}`))
+	req.Header.Set(HeaderContentType, "application/x-json-lines")
+	fmt.Println(NewRequest(req).Bind(&v), v.Name)
+
+	body := &bytes.Buffer{}
+	mw := multipart.NewWriter(body)
+	mw.WriteField("name", "medivh")
+	fw, _ := mw.CreateFormFile("avatar", "avatar.png")
+	fw.Write([]byte("png"))
+	mw.Close()
+	var form struct {
+		Name   string
+		Avatar *multipart.FileHeader `validate:"required"`
+	}
+	req = httptest.NewRequest(http.MethodPost, "/", body)
+	req.Header.Set(HeaderContentType, mw.FormDataContentType())
+	fmt.Println(NewRequest(req).Bind(&form), form.Name, form.Avatar.Filename, form.Avatar.Size)
+
+	// Output:
+	// <nil> medivh
+	// <nil> medivh avatar.png 3
+}
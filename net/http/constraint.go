@@ -0,0 +1,88 @@
+package http
+
+import (
+	"encoding/hex"
+	"regexp"
+	"sync"
+)
+
+// Constraint reports whether a path param value is acceptable, e.g. `/users/:id<int>`.
+type Constraint func(value string) bool
+
+var (
+	constraintsMutex sync.RWMutex
+	constraints      = map[string]Constraint{
+		"int":   isIntParam,
+		"uuid":  isUUIDParam,
+		"alpha": regexpConstraint(`[a-zA-Z]+`),
+		"alnum": regexpConstraint(`[a-zA-Z0-9]+`),
+	}
+)
+
+// RegisterConstraint registers a named constraint,
+// it must be called before the routes using it are registered.
+func RegisterConstraint(name string, c Constraint) {
+	constraintsMutex.Lock()
+	defer constraintsMutex.Unlock()
+	constraints[name] = c
+}
+
+// lookupConstraint returns the registered constraint of name,
+// an unregistered name is compiled as a regexp matching the whole value.
+func lookupConstraint(name string) (Constraint, error) {
+	constraintsMutex.RLock()
+	c, ok := constraints[name]
+	constraintsMutex.RUnlock()
+	if ok {
+		return c, nil
+	}
+	re, err := regexp.Compile("^(?:" + name + ")$")
+	if err != nil {
+		return nil, errorf("invalid constraint %q: %w", name, err)
+	}
+	return re.MatchString, nil
+}
+
+func regexpConstraint(expr string) Constraint {
+	return regexp.MustCompile("^(?:" + expr + ")$").MatchString
+}
+
+func isIntParam(s string) bool {
+	if s != "" && (s[0] == '-' || s[0] == '+') {
+		s = s[1:]
+	}
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func isUUIDParam(s string) bool {
+	_, err := ParseUUID(s)
+	return err == nil
+}
+
+type UUID [16]byte
+
+// ParseUUID parses the canonical form xxxxxxxx-xxxx-xxxx-xxxx-xxxxxxxxxxxx.
+func ParseUUID(s string) (UUID, error) {
+	var u UUID
+	if len(s) != 36 || s[8] != '-' || s[13] != '-' || s[18] != '-' || s[23] != '-' {
+		return u, errorf("invalid uuid %q", s)
+	}
+	b := []byte(s[0:8] + s[9:13] + s[14:18] + s[19:23] + s[24:])
+	if _, err := hex.Decode(u[:], b); err != nil {
+		return u, errorf("invalid uuid %q", s)
+	}
+	return u, nil
+}
+
+func (u UUID) String() string {
+	s := hex.EncodeToString(u[:])
+	return s[0:8] + "-" + s[8:12] + "-" + s[12:16] + "-" + s[16:20] + "-" + s[20:]
+}
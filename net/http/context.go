@@ -112,7 +112,7 @@ func (c *contextImpl) Cookie(name string) (*http.Cookie, error) {
 }
 
 func (c *contextImpl) Bind(i interface{}) error {
-	panic("not implemented")
+	return NewRequest(c.request).Bind(i)
 }
 
 func (c *contextImpl) Text(code int, text string) error {
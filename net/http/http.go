@@ -115,6 +115,8 @@ var (
 	ErrRequireSliceType    = errorf("require slice type")
 	ErrRequireStructType   = errorf("require struct type")
 	ErrCannotSetValue      = errorf("can not set value")
+	ErrParamNotFound       = errorf("param not found")
+	ErrRouteNotFound       = errorf("route not found")
 )
 
 var BindingTagKey = "binding"
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"encoding/xml"
 	"net/http"
+	"strconv"
 
 	"github.com/medivhyang/golib/reflect/binding"
 )
@@ -124,6 +125,46 @@ func (r *Request) ParamExists(key string) bool {
 	return ok
 }
 
+func (r *Request) ParamInt(key string) (int, error) {
+	v, err := r.paramInt(key, strconv.IntSize)
+	return int(v), err
+}
+
+func (r *Request) ParamInt64(key string) (int64, error) {
+	return r.paramInt(key, 64)
+}
+
+func (r *Request) paramInt(key string, bitSize int) (int64, error) {
+	s, err := r.requireParam(key)
+	if err != nil {
+		return 0, err
+	}
+	v, err := strconv.ParseInt(s, 10, bitSize)
+	if err != nil {
+		return 0, errorf("param %s: %w", key, err)
+	}
+	return v, nil
+}
+
+func (r *Request) ParamUUID(key string) (UUID, error) {
+	s, err := r.requireParam(key)
+	if err != nil {
+		return UUID{}, err
+	}
+	u, err := ParseUUID(s)
+	if err != nil {
+		return UUID{}, errorf("param %s: %w", key, err)
+	}
+	return u, nil
+}
+
+func (r *Request) requireParam(key string) (string, error) {
+	if !r.ParamExists(key) {
+		return "", errorf("param %s: %w", key, ErrParamNotFound)
+	}
+	return r.params[key], nil
+}
+
 func (r *Request) Query(key string) string {
 	return r.raw.URL.Query().Get(key)
 }
@@ -5,7 +5,7 @@ import (
 	"context"
 	"fmt"
 	"net/http"
-	"regexp"
+	"net/url"
 	"sort"
 	"strings"
 )
@@ -15,46 +15,28 @@ import (
 type (
 	Router struct {
 		prefix          string
-		entryMap        map[key]*entry
+		trees           map[string]*node
 		entries         []*entry
+		names           map[string]string
+		last            *entry
 		midwares        []Midware
 		notFoundHandler HandlerFunc
-	}
-	key struct {
-		method string
-		path   string
+		// methodNotAllowedHandler and optionsHandler are called with the Allow header set.
+		methodNotAllowedHandler HandlerFunc
+		optionsHandler          HandlerFunc
+		noAutoHead              bool
+		// paths matches the patterns of every method with anonymous params, unmatched paths skip allowedMethods.
+		paths *node
 	}
 	entry struct {
 		method      string
 		pattern     string
+		name        string
 		handler     HandlerFunc
 		middlewares []Midware
-		regexp      *regexp.Regexp
 	}
 )
 
-func (e *entry) regexpMatch(path string) bool {
-	if e.regexp == nil {
-		e.regexp = regexp.MustCompile(parsePatternToRegexp(e.pattern))
-	}
-	return e.regexp.MatchString(path)
-}
-
-func (e *entry) params(path string) map[string]string {
-	if e.regexp == nil {
-		e.regexp = regexp.MustCompile(parsePatternToRegexp(e.pattern))
-	}
-	matches := e.regexp.FindStringSubmatch(path)
-	names := e.regexp.SubexpNames()
-	result := map[string]string{}
-	for i, name := range names {
-		if i > 0 {
-			result[name] = matches[i]
-		}
-	}
-	return result
-}
-
 func NewRouter(middlewares ...Midware) *Router {
 	r := &Router{}
 	r.Use(middlewares...)
@@ -71,6 +53,22 @@ func (router *Router) HandleNotFound(h HandlerFunc) *Router {
 	return router
 }
 
+func (router *Router) HandleMethodNotAllowed(h HandlerFunc) *Router {
+	router.methodNotAllowedHandler = h
+	return router
+}
+
+func (router *Router) HandleOptions(h HandlerFunc) *Router {
+	router.optionsHandler = h
+	return router
+}
+
+// AutoHead sets whether HEAD requests are served by GET handlers with the body discarded, default is true.
+func (router *Router) AutoHead(enabled bool) *Router {
+	router.noAutoHead = !enabled
+	return router
+}
+
 func (router *Router) Use(midwares ...Midware) *Router {
 	router.midwares = append(router.midwares, midwares...)
 	return router
@@ -89,56 +87,187 @@ func (router *Router) handle(method string, path string, handler HandlerFunc, mi
 		handler:     handler,
 		middlewares: middlewares,
 	}
-	k := key{
-		method: method,
-		path:   path,
+	if router.trees == nil {
+		router.trees = map[string]*node{}
 	}
-	if router.entryMap == nil {
-		router.entryMap = map[key]*entry{}
+	root := router.trees[method]
+	if root == nil {
+		root = &node{}
+		router.trees[method] = root
 	}
-	router.entryMap[k] = &e
-	router.entries = appendSorted(router.entries, &e)
+	root.insert(path, &e)
+	if method != "" {
+		if router.paths == nil {
+			router.paths = &node{}
+		}
+		router.paths.insert(anonymousPattern(path), &e)
+	}
+	router.entries = appendEntry(router.entries, &e)
+	router.last = &e
 	return router
 }
 
+// Name names the routes of the last registered pattern for building urls by URL.
+func (router *Router) Name(name string) *Router {
+	if router.last == nil {
+		panic(fmt.Sprintf("http: name %q without route", name))
+	}
+	pattern := router.last.pattern
+	if p, ok := router.names[name]; ok && p != pattern {
+		panic(fmt.Sprintf("http: name %q of pattern %q conflicts with pattern %q", name, pattern, p))
+	}
+	if router.names == nil {
+		router.names = map[string]string{}
+	}
+	for _, e := range router.entries {
+		if e.pattern == pattern {
+			e.name = name
+		}
+	}
+	router.names[name] = pattern
+	return router
+}
+
+// URL builds the path of the named route with params, including the router prefix.
+func (router *Router) URL(name string, params map[string]string) (string, error) {
+	pattern, ok := router.names[name]
+	if !ok {
+		return "", errorf("url %s: %w", name, ErrRouteNotFound)
+	}
+	builder := strings.Builder{}
+	builder.WriteString(router.prefix)
+	for s := pattern; ; {
+		i := strings.IndexAny(s, ":*")
+		if i < 0 {
+			builder.WriteString(s)
+			break
+		}
+		builder.WriteString(s[:i])
+		kind := s[i]
+		key, constraint, rest, err := parseParam(s[i+1:])
+		if err != nil {
+			return "", errorf("url %s: %w", name, err)
+		}
+		s = rest
+		value, ok := params[key]
+		if !ok || (value == "" && kind == ':') {
+			return "", errorf("url %s: param %s: %w", name, key, ErrParamNotFound)
+		}
+		if constraint != "" {
+			match, err := lookupConstraint(constraint)
+			if err != nil {
+				return "", errorf("url %s: %w", name, err)
+			}
+			if !match(value) {
+				return "", errorf("url %s: param %s: value %q not match constraint %q", name, key, value, constraint)
+			}
+		}
+		if kind == '*' {
+			segments := strings.Split(value, "/")
+			for j := range segments {
+				segments[j] = url.PathEscape(segments[j])
+			}
+			builder.WriteString(strings.Join(segments, "/"))
+			continue
+		}
+		builder.WriteString(url.PathEscape(value))
+	}
+	return builder.String(), nil
+}
+
+// lookup finds the entry of method and path, routes registered with an empty method match any method.
+func (router *Router) lookup(method string, path string) (*entry, []param) {
+	if root := router.trees[method]; root != nil {
+		if e, params := root.lookup(path, nil); e != nil {
+			return e, params
+		}
+	}
+	if root := router.trees[""]; root != nil && method != "" {
+		return root.lookup(path, nil)
+	}
+	return nil, nil
+}
+
+// allowedMethods returns the sorted methods that have a route matching path.
+func (router *Router) allowedMethods(path string) []string {
+	if router.paths == nil {
+		return nil
+	}
+	if e, _ := router.paths.lookup(path, nil); e == nil {
+		return nil
+	}
+	var methods []string
+	for method, root := range router.trees {
+		if method == "" {
+			continue
+		}
+		if e, _ := root.lookup(path, nil); e != nil {
+			methods = append(methods, method)
+		}
+	}
+	if len(methods) == 0 {
+		return nil
+	}
+	if !router.noAutoHead && containsString(methods, http.MethodGet) && !containsString(methods, http.MethodHead) {
+		methods = append(methods, http.MethodHead)
+	}
+	if !containsString(methods, http.MethodOptions) {
+		methods = append(methods, http.MethodOptions)
+	}
+	sort.Slice(methods, func(i, j int) bool {
+		oi, ok1 := methodOrders[methods[i]]
+		oj, ok2 := methodOrders[methods[j]]
+		if ok1 != ok2 {
+			return ok1
+		}
+		if oi != oj {
+			return oi < oj
+		}
+		return methods[i] < methods[j]
+	})
+	return methods
+}
+
 func (router *Router) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	method, path := r.Method, r.URL.Path
 	var e *entry
 	if strings.HasPrefix(path, router.prefix) {
-		path = strings.TrimPrefix(path, router.prefix)
-		k := key{
-			method: method,
-			path:   path,
+		path = path[len(router.prefix):]
+		var params []param
+		e, params = router.lookup(method, path)
+		if e == nil && method == http.MethodHead && !router.noAutoHead {
+			if e, params = router.lookup(http.MethodGet, path); e != nil {
+				w = headResponseWriter{w}
+			}
 		}
-		if router.entryMap == nil {
-			router.entryMap = map[key]*entry{}
+		if e != nil && len(params) > 0 {
+			m := make(map[string]string, len(params))
+			for _, p := range params {
+				m[p.name] = p.value
+			}
+			r = r.WithContext(context.WithValue(r.Context(), paramsContextKeySingleton, m))
 		}
-		e = router.entryMap[k]
 		if e == nil {
-			regexpMatch := false
-			for _, v := range router.entries {
-				if v.regexpMatch(path) && v.method == method {
-					regexpMatch = true
-					e = v
-					break
+			if allowed := router.allowedMethods(path); len(allowed) > 0 {
+				w.Header().Set(HeaderAllow, strings.Join(allowed, ", "))
+				if method == http.MethodOptions {
+					e = &entry{method: method, handler: router.optionsHandler}
+					if e.handler == nil {
+						e.handler = defaultOptionsHandleFunc
+					}
+				} else {
+					e = &entry{method: method, handler: router.methodNotAllowedHandler}
+					if e.handler == nil {
+						e.handler = defaultMethodNotAllowedHandleFunc
+					}
 				}
 			}
-			if regexpMatch && e != nil {
-				r = r.WithContext(context.WithValue(r.Context(), paramsContextKeySingleton, e.params(path)))
-			}
 		}
 	}
 	if e == nil {
-		if method == http.MethodOptions {
-			e = &entry{
-				method:  http.MethodOptions,
-				handler: defaultOptionsHandleFunc,
-			}
-		} else {
-			e = &entry{
-				method:  method,
-				handler: defaultNotFoundHandleFunc,
-			}
+		e = &entry{method: method, handler: router.notFoundHandler}
+		if e.handler == nil {
+			e.handler = defaultNotFoundHandleFunc
 		}
 	}
 	finalMiddlewares := append([]Midware{}, router.midwares...)
@@ -193,6 +322,7 @@ func (router *Router) Options(pattern string, handler HandlerFunc, middlewares .
 type EntryView struct {
 	Method  string `json:"method"`
 	Pattern string `json:"pattern"`
+	Name    string `json:"name,omitempty"`
 }
 
 var methodOrders = map[string]int{
@@ -228,6 +358,7 @@ func (router *Router) Items() []EntryView {
 		result = append(result, EntryView{
 			Method:  method,
 			Pattern: pattern,
+			Name:    item.name,
 		})
 	}
 	return result
@@ -237,6 +368,10 @@ func (router *Router) String() string {
 	builder := bytes.Buffer{}
 	items := router.Items()
 	for _, item := range items {
+		if item.Name != "" {
+			builder.WriteString(fmt.Sprintf("%-7s %s (%s)\n", item.Method, item.Pattern, item.Name))
+			continue
+		}
 		builder.WriteString(fmt.Sprintf("%-7s %s\n", item.Method, item.Pattern))
 	}
 	return strings.TrimSuffix(builder.String(), "\n")
@@ -268,6 +403,11 @@ func (g *group) Handle(method string, path string, handler HandlerFunc, middlewa
 	return g
 }
 
+func (g *group) Name(name string) *group {
+	g.router.Name(name)
+	return g
+}
+
 func (g *group) Group(prefix string) *group {
 	return &group{router: g.router, prefix: g.prefix + normalizePrefix(prefix)}
 }
@@ -336,23 +476,15 @@ func Params(r *http.Request) map[string]string {
 
 // region utils
 
-var (
-	namedParamRegexp    = regexp.MustCompile(":([^/]+)")
-	wildcardParamRegexp = regexp.MustCompile("\\*([^/]+)")
-)
-
-func parsePatternToRegexp(pattern string) string {
-	s := namedParamRegexp.ReplaceAllString(pattern, "(?P<$1>[^/]+)")
-	s = wildcardParamRegexp.ReplaceAllString(s, "(?P<$1>.*)")
-	s = "^" + s + "$"
-	return s
-}
-
 func defaultOptionsHandleFunc(w *ResponseWriter, r *Request) {
 	w.Header("Content-Length", "0")
 	w.StatusCode(http.StatusNoContent)
 }
 
+func defaultMethodNotAllowedHandleFunc(w *ResponseWriter, r *Request) {
+	w.Text(http.StatusMethodNotAllowed, http.StatusText(http.StatusMethodNotAllowed))
+}
+
 func defaultNotFoundHandleFunc(w *ResponseWriter, r *Request) {
 	w.Text(http.StatusNotFound, http.StatusText(http.StatusNotFound))
 }
@@ -378,30 +510,68 @@ func normalizePrefix(p string) string {
 	return p
 }
 
-func appendSorted(es []*entry, e *entry) []*entry {
-	n := len(es)
-	findIndex := sort.Search(n, func(i int) bool {
-		return es[i].method == e.method && es[i].pattern == e.pattern
-	})
-	if findIndex < n {
-		es[findIndex] = e
-		return es
-	}
-	smallestIndex := sort.Search(n, func(i int) bool {
-		l1 := len(strings.Split(es[i].pattern, "/"))
-		l2 := len(strings.Split(e.pattern, "/"))
-		if l1 != l2 {
-			return l1 < l2
+// headResponseWriter discards the body written by a GET handler serving a HEAD request.
+type headResponseWriter struct {
+	http.ResponseWriter
+}
+
+func (w headResponseWriter) Write(p []byte) (int, error) {
+	return len(p), nil
+}
+
+func (w headResponseWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (w headResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// anonymousPattern renames the params of pattern, so that patterns of different methods share a tree without conflicts.
+func anonymousPattern(pattern string) string {
+	builder := strings.Builder{}
+	for s := pattern; ; {
+		i := strings.IndexAny(s, ":*")
+		if i < 0 {
+			builder.WriteString(s)
+			break
+		}
+		builder.WriteString(s[:i+1])
+		_, constraint, rest, err := parseParam(s[i+1:])
+		if err != nil {
+			return pattern
+		}
+		builder.WriteString("_")
+		if constraint != "" {
+			builder.WriteString("<" + constraint + ">")
+		}
+		s = rest
+	}
+	return builder.String()
+}
+
+func appendEntry(es []*entry, e *entry) []*entry {
+	for i, v := range es {
+		if v.method == e.method && v.pattern == e.pattern {
+			if e.name == "" {
+				e.name = v.name
+			}
+			es[i] = e
+			return es
+		}
+	}
+	return append(es, e)
+}
+
+func containsString(ss []string, s string) bool {
+	for _, v := range ss {
+		if v == s {
+			return true
 		}
-		return len(es[i].pattern) < len(e.pattern)
-	})
-	if smallestIndex == n {
-		return append(es, e)
 	}
-	es = append(es, nil)
-	copy(es[smallestIndex+1:], es[smallestIndex:])
-	es[smallestIndex] = e
-	return es
+	return false
 }
 
 func chain(h HandlerFunc, middlewares ...Midware) HandlerFunc {
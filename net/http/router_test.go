@@ -0,0 +1,361 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func ExampleRouter() {
+	router := NewRouter()
+	show := func(name string) HandlerFunc {
+		return func(w *ResponseWriter, r *Request) {
+			w.Text(http.StatusOK, fmt.Sprintf("%s %v", name, Params(r.Raw())))
+		}
+	}
+	router.Get("/users/new", show("new"))
+	router.Get("/users/:id", show("show"))
+	router.Get("/users/:id/posts", show("posts"))
+	router.Get("/static/*path", show("static"))
+	router.Get("/*path", show("fallback"))
+
+	for _, path := range []string{"/users/new", "/users/1", "/users/1/posts", "/static/css/app.css", "/about", "/users/1/comments"} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		fmt.Println(path, "=>", w.Code, w.Body.String())
+	}
+
+	// Output:
+	// /users/new => 200 new map[]
+	// /users/1 => 200 show map[id:1]
+	// /users/1/posts => 200 posts map[id:1]
+	// /static/css/app.css => 200 static map[path:css/app.css]
+	// /about => 200 fallback map[path:about]
+	// /users/1/comments => 200 fallback map[path:users/1/comments]
+}
+
+func ExampleRouter_methodNotAllowed() {
+	router := NewRouter()
+	router.Get("/users/:id", func(w *ResponseWriter, r *Request) {
+		w.Text(http.StatusOK, "user "+r.Param("id"))
+	})
+	router.Delete("/users/:id", func(w *ResponseWriter, r *Request) {
+		w.StatusCode(http.StatusNoContent)
+	})
+
+	for _, method := range []string{http.MethodPost, http.MethodOptions, http.MethodHead} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(method, "/users/1", nil))
+		fmt.Printf("%s => %d %q allow=%q\n", method, w.Code, w.Body.String(), w.Header().Get(HeaderAllow))
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodOptions, "/posts", nil))
+	fmt.Printf("OPTIONS /posts => %d\n", w.Code)
+
+	router.HandleMethodNotAllowed(func(w *ResponseWriter, r *Request) {
+		w.JSON(http.StatusMethodNotAllowed, map[string]string{"allow": w.Raw().Header().Get(HeaderAllow)})
+	})
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPut, "/users/1", nil))
+	fmt.Printf("PUT => %d %s", w.Code, w.Body.String())
+
+	// Output:
+	// POST => 405 "Method Not Allowed" allow="GET, HEAD, DELETE, OPTIONS"
+	// OPTIONS => 204 "" allow="GET, HEAD, DELETE, OPTIONS"
+	// HEAD => 200 "" allow=""
+	// OPTIONS /posts => 404
+	// PUT => 405 {"allow":"GET, HEAD, DELETE, OPTIONS"}
+}
+
+func ExampleRouter_constraint() {
+	RegisterConstraint("even", func(s string) bool {
+		n, err := strconv.Atoi(s)
+		return err == nil && n%2 == 0
+	})
+	router := NewRouter()
+	router.Get("/users/:id<int>", func(w *ResponseWriter, r *Request) {
+		id, err := r.ParamInt64("id")
+		w.Text(http.StatusOK, fmt.Sprintf("user %d %v", id, err))
+	})
+	router.Get("/users/:slug<[a-z-]+>", func(w *ResponseWriter, r *Request) {
+		_, err := r.ParamInt("slug")
+		w.Text(http.StatusOK, fmt.Sprintf("slug %s %v", r.Param("slug"), err != nil))
+	})
+	router.Get("/tokens/:token<uuid>", func(w *ResponseWriter, r *Request) {
+		u, err := r.ParamUUID("token")
+		w.Text(http.StatusOK, fmt.Sprintf("token %s %v", u, err))
+	})
+	router.Get("/pages/:n<even>", func(w *ResponseWriter, r *Request) {
+		_, err := r.ParamInt("missing")
+		w.Text(http.StatusOK, fmt.Sprintf("page %s %v", r.Param("n"), errors.Is(err, ErrParamNotFound)))
+	})
+
+	for _, path := range []string{"/users/42", "/users/hello-world", "/users/Hello", "/tokens/6BA7B810-9DAD-11D1-80B4-00C04FD430C8", "/tokens/123", "/pages/2", "/pages/3"} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		fmt.Println(path, "=>", w.Code, w.Body.String())
+	}
+
+	// Output:
+	// /users/42 => 200 user 42 <nil>
+	// /users/hello-world => 200 slug hello-world true
+	// /users/Hello => 404 Not Found
+	// /tokens/6BA7B810-9DAD-11D1-80B4-00C04FD430C8 => 200 token 6ba7b810-9dad-11d1-80b4-00c04fd430c8 <nil>
+	// /tokens/123 => 404 Not Found
+	// /pages/2 => 200 page 2 true
+	// /pages/3 => 404 Not Found
+}
+
+func ExampleRouter_URL() {
+	noop := func(w *ResponseWriter, r *Request) {}
+	router := NewRouter().Prefix("/api")
+	router.Get("/users/:id<int>", noop).Name("user.show")
+	router.Get("/files/*path", noop).Name("file")
+	router.Group("/orgs/:org").Get("/repos/:repo", noop).Name("repo")
+
+	fmt.Println(router.URL("user.show", map[string]string{"id": "42"}))
+	fmt.Println(router.URL("file", map[string]string{"path": "docs/a b.txt"}))
+	fmt.Println(router.URL("repo", map[string]string{"org": "golang", "repo": "go"}))
+	fmt.Println(router.URL("repo", map[string]string{"org": "golang"}))
+	fmt.Println(router.URL("user.show", map[string]string{"id": "abc"}))
+	fmt.Println(router.URL("user.edit", nil))
+	fmt.Println(router)
+
+	// Output:
+	// /api/users/42 <nil>
+	// /api/files/docs/a%20b.txt <nil>
+	// /api/orgs/golang/repos/go <nil>
+	//  http: url repo: param repo: http: param not found
+	//  http: url user.show: param id: value "abc" not match constraint "int"
+	//  http: url user.edit: http: route not found
+	// GET     /api/files/*path (file)
+	// GET     /api/orgs/:org/repos/:repo (repo)
+	// GET     /api/users/:id<int> (user.show)
+}
+
+var benchRoutes = []string{
+	"/",
+	"/users",
+	"/users/new",
+	"/users/:id",
+	"/users/:id/posts",
+	"/users/:id/posts/:post",
+	"/orgs/:org/repos/:repo/issues",
+	"/orgs/:org/repos/:repo/pulls",
+	"/search",
+	"/static/*path",
+}
+
+// linearRouter is the regexp scanning router that the radix tree replaced, kept for comparison.
+type linearRouter struct {
+	entries []*regexp.Regexp
+}
+
+func newLinearRouter(patterns []string) *linearRouter {
+	named := regexp.MustCompile(":([^/]+)")
+	wildcard := regexp.MustCompile(`\*([^/]+)`)
+	r := &linearRouter{}
+	for _, p := range patterns {
+		s := named.ReplaceAllString(p, "(?P<$1>[^/]+)")
+		s = wildcard.ReplaceAllString(s, "(?P<$1>.*)")
+		r.entries = append(r.entries, regexp.MustCompile("^"+s+"$"))
+	}
+	return r
+}
+
+func (r *linearRouter) lookup(path string) map[string]string {
+	for _, e := range r.entries {
+		if matches := e.FindStringSubmatch(path); matches != nil {
+			result := map[string]string{}
+			for i, name := range e.SubexpNames() {
+				if i > 0 {
+					result[name] = matches[i]
+				}
+			}
+			return result
+		}
+	}
+	return nil
+}
+
+func benchRouter() *Router {
+	router := NewRouter()
+	for _, p := range benchRoutes {
+		router.Get(p, func(w *ResponseWriter, r *Request) {})
+	}
+	return router
+}
+
+func BenchmarkRouter_Static(b *testing.B) {
+	router := benchRouter()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if e, _ := router.lookup(http.MethodGet, "/search"); e == nil {
+			b.Fatal("not found")
+		}
+	}
+}
+
+func BenchmarkRouter_Param(b *testing.B) {
+	router := benchRouter()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if e, _ := router.lookup(http.MethodGet, "/orgs/golang/repos/go/pulls"); e == nil {
+			b.Fatal("not found")
+		}
+	}
+}
+
+func BenchmarkLinearRouter_Static(b *testing.B) {
+	router := newLinearRouter(benchRoutes)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if router.lookup("/search") == nil {
+			b.Fatal("not found")
+		}
+	}
+}
+
+func BenchmarkLinearRouter_Param(b *testing.B) {
+	router := newLinearRouter(benchRoutes)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if router.lookup("/orgs/golang/repos/go/pulls") == nil {
+			b.Fatal("not found")
+		}
+	}
+}
+
+func TestRouter_Lookup(t *testing.T) {
+	router := benchRouter()
+	linear := newLinearRouter(benchRoutes)
+	for _, path := range []string{"/", "/users", "/users/new", "/users/42", "/users/42/posts/7", "/orgs/a/repos/b/issues", "/static/", "/static/a/b", "/nope"} {
+		e, params := router.lookup(http.MethodGet, path)
+		want := linear.lookup(path)
+		if (e == nil) != (want == nil) {
+			t.Fatalf("%s: got %v, want %v", path, e, want)
+		}
+		var got []string
+		for _, p := range params {
+			got = append(got, p.name+"="+p.value)
+		}
+		if len(got) != len(want) {
+			t.Fatalf("%s: got params %s, want %v", path, strings.Join(got, ","), want)
+		}
+		for _, p := range params {
+			if want[p.name] != p.value {
+				t.Fatalf("%s: got param %s=%s, want %s", path, p.name, p.value, want[p.name])
+			}
+		}
+	}
+}
+
+func TestRouter_ParamNames(t *testing.T) {
+	router := NewRouter()
+	show := func(w *ResponseWriter, r *Request) {
+		w.Text(http.StatusOK, fmt.Sprint(Params(r.Raw())))
+	}
+	router.Get("/users/:id", show).Get("/users/:uid/posts", show).Get("/users/:name/files/*path", show)
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"/users/1", "map[id:1]"},
+		{"/users/1/posts", "map[uid:1]"},
+		{"/users/a/files/b/c", "map[name:a path:b/c]"},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+		if got := w.Body.String(); got != tt.want {
+			t.Errorf("%s: got %s, want %s", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestRouter_Conflict(t *testing.T) {
+	tests := []struct {
+		first, second string
+	}{
+		{"/users/:id", "/users/:name"},
+		{"/users/:id/posts", "/users/:name/posts"},
+		{"/files/*path", "/files/*name"},
+		{"/files/*path<int>", "/files/*path"},
+	}
+	for _, tt := range tests {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%s, %s: expected panic", tt.first, tt.second)
+				}
+			}()
+			NewRouter().Get(tt.first, nil).Get(tt.second, nil)
+		}()
+	}
+}
+
+func TestRouter_InvalidConstraint(t *testing.T) {
+	for _, pattern := range []string{"/users/:id<int", "/users/:id<>", "/users/:id<int>x", "/users/:id<[a-z>"} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%s: expected panic", pattern)
+				}
+			}()
+			NewRouter().Get(pattern, nil)
+		}()
+	}
+}
+
+func TestRouter_AllowedMethods(t *testing.T) {
+	router := NewRouter()
+	router.Get("/users/:id", nil)
+	router.Delete("/users/:uid", nil)
+	router.Post("/users/new", nil)
+	router.Get("/files/*path", nil)
+	router.Put("/files/*name", nil)
+	router.Patch("/items/:id<int>", nil)
+	router.Handle("", "/any", nil)
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"/users/1", "GET, HEAD, DELETE, OPTIONS"},
+		{"/users/new", "GET, HEAD, POST, DELETE, OPTIONS"},
+		{"/files/a/b", "GET, HEAD, PUT, OPTIONS"},
+		{"/items/1", "PATCH, OPTIONS"},
+		{"/items/x", ""},
+		{"/any", ""},
+		{"/nope", ""},
+	}
+	for _, tt := range tests {
+		if got := strings.Join(router.allowedMethods(tt.path), ", "); got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.path, got, tt.want)
+		}
+	}
+	if got := NewRouter().allowedMethods("/"); got != nil {
+		t.Errorf("got %q from an empty router, want none", got)
+	}
+}
+
+func TestRouter_HeadFlush(t *testing.T) {
+	router := NewRouter()
+	router.Get("/events", func(w *ResponseWriter, r *Request) {
+		f, ok := w.Raw().(http.Flusher)
+		if !ok {
+			t.Fatal("got response writer without http.Flusher")
+		}
+		w.Raw().Write([]byte("data"))
+		f.Flush()
+	})
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodHead, "/events", nil))
+	if !w.Flushed || w.Body.Len() != 0 {
+		t.Fatalf("got flushed %v body %q, want flushed without body", w.Flushed, w.Body.String())
+	}
+}
@@ -0,0 +1,206 @@
+package http
+
+import (
+	"fmt"
+	"strings"
+)
+
+// node is a node of the radix tree of routes, static children are matched first,
+// then the constrained params, the unconstrained param and the wildcard at last.
+// Params are named by the node of the entry, so that patterns can name the same segment differently.
+type node struct {
+	prefix     string
+	indices    string
+	children   []*node
+	params     []*node
+	wildcard   *node
+	constraint string
+	match      Constraint
+	entry      *entry
+	names      []string
+}
+
+type param struct {
+	name  string
+	value string
+}
+
+func (n *node) insert(pattern string, e *entry) {
+	current := n
+	var names []string
+	for s := pattern; ; {
+		i := strings.IndexAny(s, ":*")
+		if i < 0 {
+			current = current.insertStatic(s)
+			break
+		}
+		current = current.insertStatic(s[:i])
+		kind := s[i]
+		name, constraint, rest, err := parseParam(s[i+1:])
+		if err != nil {
+			panic(fmt.Sprintf("http: %v in pattern %q", err, pattern))
+		}
+		names = append(names, name)
+		child := &node{constraint: constraint}
+		if constraint != "" {
+			if child.match, err = lookupConstraint(constraint); err != nil {
+				panic(fmt.Sprintf("%v in pattern %q", err, pattern))
+			}
+		}
+		s = rest
+		if kind == '*' {
+			if s != "" {
+				panic(fmt.Sprintf("http: wildcard must be the last segment in pattern %q", pattern))
+			}
+			if current.wildcard == nil {
+				current.wildcard = child
+			} else if current.wildcard.constraint != constraint {
+				panic(fmt.Sprintf("http: wildcard %q in pattern %q conflicts with existing wildcard constraint %q", name, pattern, current.wildcard.constraint))
+			}
+			current = current.wildcard
+			break
+		}
+		current = current.insertParam(child)
+	}
+	if current.entry != nil && !equalStrings(current.names, names) {
+		panic(fmt.Sprintf("http: params %v of pattern %q conflict with existing params %v", names, pattern, current.names))
+	}
+	current.entry = e
+	current.names = names
+}
+
+// parseParam parses `name` or `name<constraint>` at the beginning of s, which must end the path segment.
+func parseParam(s string) (name string, constraint string, rest string, err error) {
+	end := strings.IndexAny(s, "/<")
+	if end < 0 {
+		end = len(s)
+	}
+	name, rest = s[:end], s[end:]
+	if name == "" {
+		return "", "", "", fmt.Errorf("empty param name")
+	}
+	if rest == "" || rest[0] != '<' {
+		return name, "", rest, nil
+	}
+	depth := 0
+	for i := 0; i < len(rest); i++ {
+		switch rest[i] {
+		case '<':
+			depth++
+		case '>':
+			depth--
+		}
+		if depth == 0 {
+			constraint, rest = rest[1:i], rest[i+1:]
+			if constraint == "" {
+				return "", "", "", fmt.Errorf("empty constraint of param %q", name)
+			}
+			if rest != "" && rest[0] != '/' {
+				return "", "", "", fmt.Errorf("constraint of param %q must end the segment", name)
+			}
+			return name, constraint, rest, nil
+		}
+	}
+	return "", "", "", fmt.Errorf("unclosed constraint of param %q", name)
+}
+
+// insertParam returns the param child with the same constraint, constrained params are kept before the unconstrained one.
+func (n *node) insertParam(child *node) *node {
+	for _, p := range n.params {
+		if p.constraint == child.constraint {
+			return p
+		}
+	}
+	if child.constraint != "" && len(n.params) > 0 && n.params[len(n.params)-1].constraint == "" {
+		last := n.params[len(n.params)-1]
+		n.params = append(n.params[:len(n.params)-1], child, last)
+		return child
+	}
+	n.params = append(n.params, child)
+	return child
+}
+
+func (n *node) insertStatic(s string) *node {
+	if s == "" {
+		return n
+	}
+	i := strings.IndexByte(n.indices, s[0])
+	if i < 0 {
+		child := &node{prefix: s}
+		n.indices += s[:1]
+		n.children = append(n.children, child)
+		return child
+	}
+	child := n.children[i]
+	l := commonPrefixLength(child.prefix, s)
+	if l < len(child.prefix) {
+		split := *child
+		split.prefix = child.prefix[l:]
+		*child = node{prefix: child.prefix[:l], indices: split.prefix[:1], children: []*node{&split}}
+	}
+	return child.insertStatic(s[l:])
+}
+
+// lookup returns the entry matching path, params are appended only for param and wildcard segments.
+func (n *node) lookup(path string, params []param) (*entry, []param) {
+	if path == "" && n.entry != nil {
+		return n.entry, n.named(params)
+	}
+	if path != "" {
+		if i := strings.IndexByte(n.indices, path[0]); i >= 0 {
+			child := n.children[i]
+			if strings.HasPrefix(path, child.prefix) {
+				if e, ps := child.lookup(path[len(child.prefix):], params); e != nil {
+					return e, ps
+				}
+			}
+		}
+		if len(n.params) > 0 {
+			end := strings.IndexByte(path, '/')
+			if end < 0 {
+				end = len(path)
+			}
+			for _, p := range n.params {
+				if end == 0 || (p.match != nil && !p.match(path[:end])) {
+					continue
+				}
+				ps := append(params, param{value: path[:end]})
+				if e, ps := p.lookup(path[end:], ps); e != nil {
+					return e, ps
+				}
+			}
+		}
+	}
+	if n.wildcard != nil && n.wildcard.entry != nil && (n.wildcard.match == nil || n.wildcard.match(path)) {
+		return n.wildcard.entry, n.wildcard.named(append(params, param{value: path}))
+	}
+	return nil, params
+}
+
+// named sets the names of the params matched for the entry of n.
+func (n *node) named(params []param) []param {
+	for i := range params {
+		params[i].name = n.names[i]
+	}
+	return params
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func commonPrefixLength(a, b string) int {
+	i := 0
+	for i < len(a) && i < len(b) && a[i] == b[i] {
+		i++
+	}
+	return i
+}
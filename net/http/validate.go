@@ -0,0 +1,206 @@
+package http
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// ValidateFunc reports whether v satisfies the rule with param, e.g. `validate:"min=1"` calls the min rule with "1".
+type ValidateFunc func(v reflect.Value, param string) bool
+
+var (
+	validationsMutex sync.RWMutex
+	validations      = map[string]ValidateFunc{
+		"required": validateRequired,
+		"min":      validateMin,
+		"max":      validateMax,
+		"len":      validateLen,
+		"oneof":    validateOneOf,
+		"email":    validateEmail,
+	}
+)
+
+// RegisterValidation registers a named validation rule of the validate tag.
+func RegisterValidation(name string, f ValidateFunc) {
+	validationsMutex.Lock()
+	defer validationsMutex.Unlock()
+	validations[name] = f
+}
+
+func lookupValidation(name string) (ValidateFunc, bool) {
+	validationsMutex.RLock()
+	defer validationsMutex.RUnlock()
+	f, ok := validations[name]
+	return f, ok
+}
+
+// Validate checks the validate tags of the struct i, the error is a *BindError if any field is invalid.
+func Validate(i interface{}) error {
+	if fields := validateStruct(reflect.ValueOf(i), ""); len(fields) > 0 {
+		return &BindError{Status: StatusUnprocessableEntity, Message: "validation failed", Fields: fields}
+	}
+	return nil
+}
+
+func validateStruct(rv reflect.Value, prefix string) []FieldError {
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return nil
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil
+	}
+	var result []FieldError
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		sf := rt.Field(i)
+		if sf.PkgPath != "" {
+			continue
+		}
+		fv := rv.Field(i)
+		name := prefix + fieldName(sf)
+		if tag := sf.Tag.Get(ValidateTagKey); tag != "" && tag != "-" {
+			for _, rule := range strings.Split(tag, ",") {
+				if strings.TrimSpace(rule) == "omitempty" {
+					if isEmptyValue(fv) {
+						break
+					}
+					continue
+				}
+				if e, ok := validateRule(fv, name, rule); !ok {
+					result = append(result, e)
+					break
+				}
+			}
+		}
+		if ft := unrefType(sf.Type); ft.Kind() == reflect.Struct && ft != reflect.TypeOf(time.Time{}) {
+			result = append(result, validateStruct(fv, name+".")...)
+		}
+	}
+	return result
+}
+
+func validateRule(fv reflect.Value, field string, rule string) (FieldError, bool) {
+	name, param, _ := strings.Cut(strings.TrimSpace(rule), "=")
+	if name == "" {
+		return FieldError{}, true
+	}
+	if name != "required" && fv.Kind() == reflect.Ptr && fv.IsNil() {
+		return FieldError{}, true
+	}
+	f, ok := lookupValidation(name)
+	if !ok {
+		return FieldError{Field: field, Rule: name, Message: fmt.Sprintf("unknown rule %q", name)}, false
+	}
+	if f(fv, param) {
+		return FieldError{}, true
+	}
+	return FieldError{Field: field, Rule: name, Message: ruleMessage(name, param)}, false
+}
+
+func ruleMessage(name string, param string) string {
+	switch name {
+	case "required":
+		return "is required"
+	case "min":
+		return "must be at least " + param
+	case "max":
+		return "must be at most " + param
+	case "len":
+		return "must have length " + param
+	case "oneof":
+		return "must be one of " + param
+	case "email":
+		return "must be an email"
+	}
+	if param != "" {
+		return fmt.Sprintf("must satisfy %s=%s", name, param)
+	}
+	return "must satisfy " + name
+}
+
+func isEmptyValue(v reflect.Value) bool {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return true
+		}
+		v = v.Elem()
+	}
+	switch v.Kind() {
+	case reflect.Slice, reflect.Map, reflect.Array, reflect.String:
+		return v.Len() == 0
+	}
+	return v.IsZero()
+}
+
+func validateRequired(v reflect.Value, _ string) bool {
+	return !isEmptyValue(v)
+}
+
+func validateMin(v reflect.Value, param string) bool {
+	n, ok := measureValue(v)
+	limit, err := strconv.ParseFloat(param, 64)
+	return ok && err == nil && n >= limit
+}
+
+func validateMax(v reflect.Value, param string) bool {
+	n, ok := measureValue(v)
+	limit, err := strconv.ParseFloat(param, 64)
+	return ok && err == nil && n <= limit
+}
+
+func validateLen(v reflect.Value, param string) bool {
+	n, ok := measureValue(v)
+	limit, err := strconv.ParseFloat(param, 64)
+	return ok && err == nil && n == limit
+}
+
+// measureValue returns numbers as they are, and the length of strings, slices and maps.
+func measureValue(v reflect.Value) (float64, bool) {
+	for v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+	switch v.Kind() {
+	case reflect.String:
+		return float64(utf8.RuneCountInString(v.String())), true
+	case reflect.Slice, reflect.Map, reflect.Array:
+		return float64(v.Len()), true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true
+	}
+	return 0, false
+}
+
+func validateOneOf(v reflect.Value, param string) bool {
+	for v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+	s := fmt.Sprint(v.Interface())
+	for _, item := range strings.Fields(param) {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+var emailRegexp = regexp.MustCompile(`^[^@\s]+@[^@\s]+\.[^@\s]+$`)
+
+func validateEmail(v reflect.Value, _ string) bool {
+	for v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+	return v.Kind() == reflect.String && emailRegexp.MatchString(v.String())
+}